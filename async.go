@@ -0,0 +1,202 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BackpressurePolicy controls what ServeHTTP does when the async queue of a worker is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock waits for room in the queue for as long as the request is alive
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureReject responds with 503 Service Unavailable so the API redelivers the webhook later
+	BackpressureReject
+)
+
+// AsyncOptions configures asynchronous webhook processing. In async mode ServeHTTP
+// acknowledges a webhook as soon as it is queued and handlers run on a pool of workers.
+// Events of the same chat always go to the same worker and are processed in order.
+type AsyncOptions struct {
+	Workers      int                // Number of workers, asynchronous processing is disabled when zero
+	QueueSize    int                // Capacity of each worker's queue, defaults to 100
+	Backpressure BackpressurePolicy // Behavior when a queue is full, defaults to BackpressureBlock
+
+	// OnError receives errors returned by handlers, which can no longer be reported to
+	// the webhook sender. Errors are logged when unset.
+	OnError func(ev *WebhookEvent, err error)
+}
+
+var (
+	errQueueFull  = errors.New("webhook queue is full")
+	errPoolClosed = errors.New("webhook handler is shutting down")
+)
+
+// queuedEvent is an event waiting for a worker, with the source and span it was received in
+type queuedEvent struct {
+	ev     *WebhookEvent
+	source string
+	link   trace.SpanContext
+}
+
+// workerPool runs handlers on a fixed set of workers, each with its own queue
+type workerPool struct {
+	handle  Handler
+	onError func(ev *WebhookEvent, err error)
+	logger  *slog.Logger
+	policy  BackpressurePolicy
+	queues  []chan queuedEvent
+	next    uint32 // round-robin counter for events without a chat
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	// closing is closed when shutdown starts, releasing enqueue calls blocked on a full
+	// queue so that they do not hold mu
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// ctx is passed to handlers and cancelled when a shutdown deadline expires
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newWorkerPool starts the workers of an async pool
+func newWorkerPool(opts AsyncOptions, handle Handler, logger *slog.Logger) *workerPool {
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &workerPool{
+		handle:  handle,
+		onError: opts.OnError,
+		logger:  logger,
+		policy:  opts.Backpressure,
+		queues:  make([]chan queuedEvent, opts.Workers),
+		closing: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	for i := range p.queues {
+		p.queues[i] = make(chan queuedEvent, queueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+
+	return p
+}
+
+// work processes the events of one queue until it is closed
+func (p *workerPool) work(queue chan queuedEvent) {
+	defer p.wg.Done()
+
+	for item := range queue {
+		ev := item.ev
+		ctx := withEventLink(p.ctx, item.link)
+		if item.source != "" {
+			ctx = withEventSource(ctx, item.source)
+		}
+		// A panicking handler must not take down the worker and its chats' queue
+		if err := callRecovering(ctx, ev, p.handle); err != nil {
+			if p.onError != nil {
+				p.onError(ev, err)
+			} else {
+				p.logger.LogAttrs(p.ctx, slog.LevelError, "webhook handling failed", append(eventAttrs(ev), slog.Any("error", err))...)
+			}
+		}
+	}
+}
+
+// queueFor returns the queue of the worker responsible for the event's chat
+func (p *workerPool) queueFor(ev *WebhookEvent) chan queuedEvent {
+	chat := ev.Chat()
+	if chat == "" {
+		n := atomic.AddUint32(&p.next, 1)
+		return p.queues[int(n%uint32(len(p.queues)))]
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(chat))
+	return p.queues[int(h.Sum32()%uint32(len(p.queues)))]
+}
+
+// enqueue hands an event to its worker according to the backpressure policy
+func (p *workerPool) enqueue(ctx context.Context, ev *WebhookEvent) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return errPoolClosed
+	}
+
+	item := queuedEvent{ev: ev, link: trace.SpanContextFromContext(ctx)}
+	item.source, _ = ctx.Value(eventSourceKey{}).(string)
+
+	queue := p.queueFor(ev)
+	if p.policy == BackpressureReject {
+		select {
+		case queue <- item:
+			return nil
+		default:
+			return errQueueFull
+		}
+	}
+
+	select {
+	case queue <- item:
+		return nil
+	case <-p.closing:
+		return errPoolClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown stops accepting events and waits until the queued ones are processed.
+// If ctx ends first, the context of running handlers is cancelled.
+func (p *workerPool) shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.closing) })
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting webhooks in async mode and waits until every queued event
+// has been handled or ctx is done. It is a no-op for synchronous handlers.
+func (w *WebhookHandler) Shutdown(ctx context.Context) error {
+	if w.pool == nil {
+		return nil
+	}
+	return w.pool.shutdown(ctx)
+}
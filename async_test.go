@@ -0,0 +1,194 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postWebhook(handler http.Handler, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func textWebhookJSON(chatID, idMessage string) string {
+	return fmt.Sprintf(`{"typeWebhook":"incomingMessageReceived","idMessage":%q,"senderData":{"chatId":%q},"messageData":{"typeMessage":"textMessage","textMessageData":{"textMessage":"hi"}}}`, idMessage, chatID)
+}
+
+// TestWebhookHandler_AsyncPerChatOrder tests that events of one chat are handled in order and drained on shutdown
+func TestWebhookHandler_AsyncPerChatOrder(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Async: AsyncOptions{Workers: 4}})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	got := map[string][]string{}
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		got[ev.Chat()] = append(got[ev.Chat()], ev.IDMessage)
+		return nil
+	})
+
+	chats := []string{"1@c.us", "2@c.us", "3@g.us"}
+	for i := 0; i < 10; i++ {
+		for _, chat := range chats {
+			require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON(chat, fmt.Sprint(i))))
+		}
+	}
+
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	want := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	for _, chat := range chats {
+		assert.Equal(t, want, got[chat], chat)
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, postWebhook(handler, textWebhookJSON("1@c.us", "late")))
+}
+
+// TestWebhookHandler_AsyncReject tests the reject backpressure policy and error reporting
+func TestWebhookHandler_AsyncReject(t *testing.T) {
+	var failed []error
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Async: AsyncOptions{
+		Workers:      1,
+		QueueSize:    1,
+		Backpressure: BackpressureReject,
+		OnError: func(ev *WebhookEvent, err error) {
+			failed = append(failed, err)
+		},
+	}})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		started <- struct{}{}
+		<-release
+		return errors.New("handler failed")
+	})
+
+	// The first event occupies the worker, the second fills the queue
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "a")))
+	<-started
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "b")))
+	assert.Equal(t, http.StatusServiceUnavailable, postWebhook(handler, textWebhookJSON("1@c.us", "c")))
+
+	close(release)
+	<-started
+	require.NoError(t, handler.Shutdown(context.Background()))
+	assert.Len(t, failed, 2)
+}
+
+// TestWebhookHandler_AsyncShutdownDeadline tests that shutdown cancels handlers when its context ends
+func TestWebhookHandler_AsyncShutdownDeadline(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Async: AsyncOptions{Workers: 1}})
+	require.NoError(t, err)
+
+	cancelled := make(chan struct{})
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "a")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, handler.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+}
+
+// TestWebhookHandler_AsyncShutdownBlocked tests that a shutdown deadline is honoured while
+// a webhook is blocked on a full queue
+func TestWebhookHandler_AsyncShutdownBlocked(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Async: AsyncOptions{
+		Workers:      1,
+		QueueSize:    1,
+		Backpressure: BackpressureBlock,
+	}})
+	require.NoError(t, err)
+
+	started := make(chan struct{}, 1)
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "a")))
+	<-started
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "b")))
+
+	blocked := make(chan int)
+	go func() {
+		blocked <- postWebhook(handler, textWebhookJSON("1@c.us", "c"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- handler.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdown:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown ignored its deadline")
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, <-blocked)
+}
+
+// TestWebhookHandler_AsyncPanic tests that a panicking handler is reported and its worker keeps running
+func TestWebhookHandler_AsyncPanic(t *testing.T) {
+	var mu sync.Mutex
+	var failed []error
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Async: AsyncOptions{
+		Workers: 1,
+		OnError: func(ev *WebhookEvent, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, err)
+		},
+	}})
+	require.NoError(t, err)
+
+	var handled []string
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		if ev.IDMessage == "a" {
+			panic("boom")
+		}
+		handled = append(handled, ev.IDMessage)
+		return nil
+	})
+
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "a")))
+	require.Equal(t, http.StatusOK, postWebhook(handler, textWebhookJSON("1@c.us", "b")))
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"b"}, handled)
+	require.Len(t, failed, 1)
+	var panicErr *PanicError
+	require.ErrorAs(t, failed[0], &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+}
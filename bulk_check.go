@@ -0,0 +1,224 @@
+package sdkwa
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckResult is the outcome of checking one phone number with CheckWhatsAppBulk
+type CheckResult struct {
+	PhoneNumber int64 // Phone number as passed to CheckWhatsAppBulk
+	Exists      bool  // Whether a WhatsApp account exists for the number
+	Cached      bool  // Whether the result came from the cache, without an API request
+	Err         error // Error of the check, Exists being meaningless when set
+}
+
+// CheckCache stores the results of WhatsApp account checks so that repeated checks do
+// not reach the API. Implementations backed by a shared store share results across
+// processes.
+type CheckCache interface {
+	// Get returns the cached result for a phone number and whether there is one
+	Get(ctx context.Context, phoneNumber int64) (exists bool, found bool, err error)
+	// Set caches the result for a phone number
+	Set(ctx context.Context, phoneNumber int64, exists bool) error
+}
+
+// BulkCheckOptions configures CheckWhatsAppBulk
+type BulkCheckOptions struct {
+	Concurrency int        // Maximum number of concurrent checks, defaults to 4
+	Cache       CheckCache // Results cache, disabled when nil
+
+	// Previous holds the results of an interrupted run to resume: numbers with a
+	// result without error are not checked again
+	Previous []CheckResult
+
+	// OnProgress is called after each number is checked with its result and the count
+	// of numbers done so far, including resumed ones, out of total. Calls are serialized.
+	OnProgress func(result CheckResult, done, total int)
+}
+
+// CheckWhatsAppBulk checks whether WhatsApp accounts exist for many phone numbers, running
+// up to opts.Concurrency checks at once. Requests go through the client rate limiter and
+// retry policy like any other. Results are returned in the order of numbers, a number
+// appearing several times being checked once; the error of a failed check is set on its
+// result. If ctx ends, the numbers not yet checked get the context error, which is also
+// returned; pass the results as opts.Previous to resume.
+func (c *Client) CheckWhatsAppBulk(ctx context.Context, numbers []int64, opts BulkCheckOptions) ([]CheckResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	previous := make(map[int64]CheckResult, len(opts.Previous))
+	for _, result := range opts.Previous {
+		if result.Err == nil {
+			previous[result.PhoneNumber] = result
+		}
+	}
+
+	// Positions of the numbers left to check, each number being checked once
+	results := make([]CheckResult, len(numbers))
+	pending := make(map[int64][]int)
+	var queue []int64
+	done := 0
+	for i, number := range numbers {
+		if result, ok := previous[number]; ok {
+			results[i] = result
+			done++
+			continue
+		}
+		if _, ok := pending[number]; !ok {
+			queue = append(queue, number)
+		}
+		pending[number] = append(pending[number], i)
+	}
+
+	var mu sync.Mutex
+	complete := func(result CheckResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, i := range pending[result.PhoneNumber] {
+			results[i] = result
+			done++
+			if opts.OnProgress != nil {
+				opts.OnProgress(result, done, len(numbers))
+			}
+		}
+		delete(pending, result.PhoneNumber)
+	}
+
+	jobs := make(chan int64)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency && w < len(queue); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range jobs {
+				complete(c.checkNumber(ctx, number, opts.Cache))
+			}
+		}()
+	}
+
+feed:
+	for _, number := range queue {
+		select {
+		case jobs <- number:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for number, positions := range pending {
+			for _, i := range positions {
+				results[i] = CheckResult{PhoneNumber: number, Err: err}
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+// checkNumber checks one phone number, using and filling the cache when set. Cache
+// failures are logged and the API is used instead.
+func (c *Client) checkNumber(ctx context.Context, number int64, cache CheckCache) CheckResult {
+	if cache != nil {
+		exists, found, err := cache.Get(ctx, number)
+		if err != nil {
+			c.logger.WarnContext(ctx, "failed to read WhatsApp check cache", "phoneNumber", number, "error", err)
+		} else if found {
+			return CheckResult{PhoneNumber: number, Exists: exists, Cached: true}
+		}
+	}
+
+	resp, err := c.CheckWhatsApp(ctx, number)
+	if err != nil {
+		return CheckResult{PhoneNumber: number, Err: err}
+	}
+
+	if cache != nil {
+		if err := cache.Set(ctx, number, resp.ExistsWhatsApp); err != nil {
+			c.logger.WarnContext(ctx, "failed to write WhatsApp check cache", "phoneNumber", number, "error", err)
+		}
+	}
+	return CheckResult{PhoneNumber: number, Exists: resp.ExistsWhatsApp}
+}
+
+// MemoryCheckCache is an in-process CheckCache keeping results for a fixed TTL, with
+// separate TTLs for numbers with and without a WhatsApp account
+type MemoryCheckCache struct {
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+
+	mu        sync.Mutex
+	entries   map[int64]checkEntry
+	lastSweep time.Time
+}
+
+// checkEntry is a cached check result
+type checkEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+// NewMemoryCheckCache creates a MemoryCheckCache keeping numbers with an account for
+// positiveTTL, defaulting to 24 hours, and numbers without one for negativeTTL,
+// defaulting to 1 hour
+func NewMemoryCheckCache(positiveTTL, negativeTTL time.Duration) *MemoryCheckCache {
+	if positiveTTL <= 0 {
+		positiveTTL = 24 * time.Hour
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = time.Hour
+	}
+
+	return &MemoryCheckCache{
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		entries:     make(map[int64]checkEntry),
+		lastSweep:   time.Now(),
+	}
+}
+
+// Get returns the cached result for a phone number if it has not expired
+func (m *MemoryCheckCache) Get(ctx context.Context, phoneNumber int64) (bool, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[phoneNumber]
+	if !ok || !time.Now().Before(entry.expires) {
+		return false, false, nil
+	}
+	return entry.exists, true, nil
+}
+
+// Set caches the result for a phone number
+func (m *MemoryCheckCache) Set(ctx context.Context, phoneNumber int64, exists bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= m.negativeTTL {
+		m.sweep(now)
+	}
+
+	ttl := m.negativeTTL
+	if exists {
+		ttl = m.positiveTTL
+	}
+	m.entries[phoneNumber] = checkEntry{exists: exists, expires: now.Add(ttl)}
+	return nil
+}
+
+// sweep removes expired entries
+func (m *MemoryCheckCache) sweep(now time.Time) {
+	for number, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, number)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,163 @@
+package sdkwa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkServer answers checkWhatsapp requests: even numbers have an account and numbers
+// ending in 13 fail
+type checkServer struct {
+	mu       sync.Mutex
+	checked  []int64
+	inFlight int32
+	peak     int32
+	delay    time.Duration
+}
+
+func (s *checkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(s.delay)
+
+	var params struct {
+		PhoneNumber int64 `json:"phoneNumber"`
+	}
+	json.NewDecoder(r.Body).Decode(&params)
+
+	s.mu.Lock()
+	s.checked = append(s.checked, params.PhoneNumber)
+	s.mu.Unlock()
+
+	if params.PhoneNumber%100 == 13 {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"message":"invalid number"}`)
+		return
+	}
+	fmt.Fprintf(w, `{"existsWhatsapp":%t}`, params.PhoneNumber%2 == 0)
+}
+
+func (s *checkServer) checkedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.checked)
+}
+
+// TestClient_CheckWhatsAppBulk tests bounded concurrency, per-number results and progress
+func TestClient_CheckWhatsAppBulk(t *testing.T) {
+	checks := &checkServer{delay: 5 * time.Millisecond}
+	server := httptest.NewServer(checks)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	var numbers []int64
+	for n := int64(79000000000); n < 79000000020; n++ {
+		numbers = append(numbers, n)
+	}
+	numbers = append(numbers, 79000000002) // duplicate
+
+	var progress []int
+	results, err := client.CheckWhatsAppBulk(context.Background(), numbers, BulkCheckOptions{
+		Concurrency: 3,
+		OnProgress: func(result CheckResult, done, total int) {
+			assert.Equal(t, 21, total)
+			progress = append(progress, done)
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, results, 21)
+	for i, result := range results {
+		assert.Equal(t, numbers[i], result.PhoneNumber)
+		if numbers[i]%100 == 13 {
+			assert.ErrorIs(t, result.Err, ErrBadRequest)
+			continue
+		}
+		require.NoError(t, result.Err)
+		assert.Equal(t, numbers[i]%2 == 0, result.Exists, numbers[i])
+	}
+	assert.Equal(t, 20, checks.checkedCount())
+	assert.LessOrEqual(t, atomic.LoadInt32(&checks.peak), int32(3))
+	require.Len(t, progress, 21)
+	assert.Equal(t, 21, progress[20])
+}
+
+// TestClient_CheckWhatsAppBulkCache tests that cached results skip the API until they expire
+func TestClient_CheckWhatsAppBulkCache(t *testing.T) {
+	checks := &checkServer{}
+	server := httptest.NewServer(checks)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	cache := NewMemoryCheckCache(time.Hour, 20*time.Millisecond)
+	numbers := []int64{79000000000, 79000000001}
+	ctx := context.Background()
+
+	_, err := client.CheckWhatsAppBulk(ctx, numbers, BulkCheckOptions{Cache: cache})
+	require.NoError(t, err)
+	results, err := client.CheckWhatsAppBulk(ctx, numbers, BulkCheckOptions{Cache: cache})
+	require.NoError(t, err)
+	assert.Equal(t, 2, checks.checkedCount())
+	assert.True(t, results[0].Cached && results[0].Exists)
+	assert.True(t, results[1].Cached && !results[1].Exists)
+
+	// The negative result expires first
+	time.Sleep(30 * time.Millisecond)
+	results, err = client.CheckWhatsAppBulk(ctx, numbers, BulkCheckOptions{Cache: cache})
+	require.NoError(t, err)
+	assert.Equal(t, 3, checks.checkedCount())
+	assert.True(t, results[0].Cached)
+	assert.False(t, results[1].Cached)
+}
+
+// TestClient_CheckWhatsAppBulkResume tests resuming a cancelled run from its results
+func TestClient_CheckWhatsAppBulkResume(t *testing.T) {
+	checks := &checkServer{}
+	server := httptest.NewServer(checks)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	var numbers []int64
+	for n := int64(79000000000); n < 79000000010; n++ {
+		numbers = append(numbers, n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := client.CheckWhatsAppBulk(ctx, numbers, BulkCheckOptions{
+		Concurrency: 1,
+		OnProgress: func(result CheckResult, done, total int) {
+			if done == 4 {
+				cancel()
+			}
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, results, 10)
+	assert.NoError(t, results[3].Err)
+	assert.ErrorIs(t, results[9].Err, context.Canceled)
+	assert.Equal(t, 4, checks.checkedCount())
+
+	results, err = client.CheckWhatsAppBulk(context.Background(), numbers, BulkCheckOptions{Previous: results})
+	require.NoError(t, err)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, numbers[i]%2 == 0, result.Exists)
+	}
+	assert.Equal(t, 10, checks.checkedCount())
+}
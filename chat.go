@@ -70,6 +70,10 @@ func (c *Client) CheckWhatsApp(ctx context.Context, phoneNumber int64, opts ...*
 
 // ReadChat marks messages in a chat as read
 func (c *Client) ReadChat(ctx context.Context, params ReadChatParams, opts ...*RequestOptions) (*ReadChatResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+
 	var result ReadChatResponse
 	err := c.request(ctx, "POST", c.basePath+"/readChat", params, &result, opts...)
 	return &result, err
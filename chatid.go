@@ -0,0 +1,156 @@
+package sdkwa
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidChatID is matched by errors reporting a malformed chat ID
+var ErrInvalidChatID = errors.New("sdkwa: invalid chat ID")
+
+// Chat ID suffixes
+const (
+	personalChatSuffix = "@c.us"
+	groupChatSuffix    = "@g.us"
+)
+
+// ChatID identifies a chat: a personal WhatsApp chat (79001234567@c.us), a WhatsApp
+// group (79001234567-1581234048@g.us or 120363043968066561@g.us) or a Telegram chat
+// (1234567890, negative for groups). Parameter structs take chat IDs as strings, so
+// pass id.String() or string(id).
+type ChatID string
+
+// PersonalChat returns the chat ID of a phone number in international format. Every
+// character other than a digit is dropped, so "+7 (900) 123-45-67" gives 79001234567@c.us.
+func PersonalChat(phone string) ChatID {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return ChatID(digits.String() + personalChatSuffix)
+}
+
+// GroupChat returns the chat ID of a WhatsApp group, adding the @g.us suffix if missing
+func GroupChat(id string) ChatID {
+	if strings.HasSuffix(id, groupChatSuffix) {
+		return ChatID(id)
+	}
+	return ChatID(id + groupChatSuffix)
+}
+
+// TelegramChat returns the chat ID of a Telegram chat
+func TelegramChat(id int64) ChatID {
+	return ChatID(strconv.FormatInt(id, 10))
+}
+
+// ParseChatID validates a chat ID. Personal chats must be 5 to 15 digits followed by
+// @c.us, groups digits optionally followed by a dash and digits then @g.us, and Telegram
+// chats an optionally negative integer without suffix.
+func ParseChatID(s string) (ChatID, error) {
+	switch {
+	case s == "":
+		return "", fmt.Errorf("%w: empty", ErrInvalidChatID)
+
+	case strings.HasSuffix(s, personalChatSuffix):
+		phone := strings.TrimSuffix(s, personalChatSuffix)
+		if !isDigits(phone) || len(phone) < 5 || len(phone) > 15 {
+			return "", fmt.Errorf("%w %q: expected a phone number of 5 to 15 digits before @c.us", ErrInvalidChatID, s)
+		}
+
+	case strings.HasSuffix(s, groupChatSuffix):
+		creator, created, dashed := strings.Cut(strings.TrimSuffix(s, groupChatSuffix), "-")
+		if !isDigits(creator) || (dashed && !isDigits(created)) {
+			return "", fmt.Errorf("%w %q: expected digits or digits-digits before @g.us", ErrInvalidChatID, s)
+		}
+
+	case strings.Contains(s, "@"):
+		return "", fmt.Errorf("%w %q: unknown suffix, expected @c.us or @g.us", ErrInvalidChatID, s)
+
+	default:
+		if !isDigits(strings.TrimPrefix(s, "-")) {
+			return "", fmt.Errorf("%w %q: expected @c.us, @g.us or a numeric Telegram chat ID", ErrInvalidChatID, s)
+		}
+	}
+
+	return ChatID(s), nil
+}
+
+// String returns the chat ID as passed to the API
+func (id ChatID) String() string {
+	return string(id)
+}
+
+// IsGroup reports whether the chat is a WhatsApp group or a Telegram group (negative ID)
+func (id ChatID) IsGroup() bool {
+	return strings.HasSuffix(string(id), groupChatSuffix) || strings.HasPrefix(string(id), "-")
+}
+
+// Phone returns the phone number of a personal WhatsApp chat, or "" for other chats
+func (id ChatID) Phone() string {
+	phone, ok := strings.CutSuffix(string(id), personalChatSuffix)
+	if !ok {
+		return ""
+	}
+	return phone
+}
+
+// isTelegram reports whether the chat ID has the numeric form only Telegram uses
+func (id ChatID) isTelegram() bool {
+	return !strings.Contains(string(id), "@")
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// chatKind restricts the chats a chat ID parameter may refer to
+type chatKind int
+
+const (
+	anyChat chatKind = iota
+	personalChat
+	groupChat
+)
+
+// checkChatID validates a chat ID parameter before any request is made when
+// Options.ValidateChatIDs is set. Numeric Telegram chat IDs are rejected for WhatsApp,
+// taking the messenger type override of opts into account.
+func (c *Client) checkChatID(param, chatID string, kind chatKind, opts []*RequestOptions) error {
+	if !c.validateChatIDs {
+		return nil
+	}
+
+	id, err := ParseChatID(chatID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", param, err)
+	}
+
+	messengerType := c.messengerType
+	if len(opts) > 0 && opts[0] != nil && opts[0].MessengerType != "" {
+		messengerType = opts[0].MessengerType
+	}
+	if messengerType == MessengerWhatsApp && id.isTelegram() {
+		return fmt.Errorf("%s: %w %q: WhatsApp chat IDs end with @c.us or @g.us", param, ErrInvalidChatID, chatID)
+	}
+
+	switch {
+	case kind == groupChat && !id.IsGroup():
+		return fmt.Errorf("%s: %w %q: not a group chat", param, ErrInvalidChatID, chatID)
+	case kind == personalChat && id.IsGroup():
+		return fmt.Errorf("%s: %w %q: not a personal chat", param, ErrInvalidChatID, chatID)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseChatID tests chat ID validation
+func TestParseChatID(t *testing.T) {
+	valid := []string{
+		"79001234567@c.us",
+		"79001234567-1581234048@g.us",
+		"120363043968066561@g.us",
+		"1234567890",
+		"-1001234567890",
+	}
+	for _, s := range valid {
+		id, err := ParseChatID(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, ChatID(s), id)
+	}
+
+	invalid := []string{
+		"",
+		"+79001234567@c.us",
+		"1234@c.us",
+		"7900123456789012@c.us",
+		"79001234567-@g.us",
+		"group@g.us",
+		"79001234567@s.whatsapp.net",
+		"12ab",
+	}
+	for _, s := range invalid {
+		_, err := ParseChatID(s)
+		assert.ErrorIs(t, err, ErrInvalidChatID, s)
+	}
+}
+
+// TestChatID_Constructors tests the chat ID constructors and helpers
+func TestChatID_Constructors(t *testing.T) {
+	personal := PersonalChat("+7 (900) 123-45-67")
+	assert.Equal(t, ChatID("79001234567@c.us"), personal)
+	assert.Equal(t, "79001234567", personal.Phone())
+	assert.False(t, personal.IsGroup())
+
+	group := GroupChat("79001234567-1581234048")
+	assert.Equal(t, ChatID("79001234567-1581234048@g.us"), group)
+	assert.Equal(t, group, GroupChat(group.String()))
+	assert.True(t, group.IsGroup())
+	assert.Empty(t, group.Phone())
+
+	assert.Equal(t, ChatID("1234567890"), TelegramChat(1234567890))
+	assert.True(t, TelegramChat(-100123).IsGroup())
+	assert.False(t, TelegramChat(100123).IsGroup())
+}
+
+// TestClient_ValidateChatIDs tests that invalid chat IDs are rejected before any request
+func TestClient_ValidateChatIDs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, server, Options{ValidateChatIDs: true})
+
+	_, err := client.SendMessage(ctx, SendMessageParams{ChatID: "79001234567", Message: "hi"})
+	assert.ErrorIs(t, err, ErrInvalidChatID)
+	assert.True(t, strings.HasPrefix(err.Error(), "chatId: "))
+
+	_, err = client.SendFileByURL(ctx, SendFileByURLParams{ChatID: "bad@c.us", URLFile: "https://example.com/a.png", FileName: "a.png"})
+	assert.ErrorIs(t, err, ErrInvalidChatID)
+
+	_, err = client.ReadChat(ctx, ReadChatParams{ChatID: ""})
+	assert.ErrorIs(t, err, ErrInvalidChatID)
+
+	_, err = client.AddGroupParticipant(ctx, "79001234567@c.us", "79001234568@c.us")
+	assert.ErrorIs(t, err, ErrInvalidChatID)
+	assert.Contains(t, err.Error(), "not a group chat")
+
+	_, err = client.CreateGroup(ctx, "Team", []string{"79001234567@c.us", "79001234567-1581234048@g.us"})
+	assert.ErrorIs(t, err, ErrInvalidChatID)
+	assert.Equal(t, 0, requests)
+
+	// Numeric chat IDs are valid for Telegram
+	_, err = client.SendMessage(ctx, SendMessageParams{ChatID: "79001234567", Message: "hi"},
+		&RequestOptions{MessengerType: MessengerTelegram})
+	require.NoError(t, err)
+	_, err = client.AddGroupParticipant(ctx, "79001234567-1581234048@g.us", "79001234568@c.us")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// Validation is disabled by default
+	client = newTestClient(t, server, Options{})
+	_, err = client.SendMessage(ctx, SendMessageParams{ChatID: "79001234567", Message: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+}
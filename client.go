@@ -5,14 +5,18 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client represents the SDKWA API client
@@ -25,6 +29,14 @@ type Client struct {
 	userToken        string
 	basePath         string
 	httpClient       *http.Client
+	retry            RetryPolicy
+	limiter          *rateLimiter
+	send             RoundTripFunc
+	hooks            ClientHooks
+	logger           *slog.Logger
+	tracer           trace.Tracer
+	metrics          Metrics
+	validateChatIDs  bool
 }
 
 // RequestOptions contains options for individual API requests
@@ -44,14 +56,48 @@ const (
 
 // Options contains configuration options for the SDKWA client
 type Options struct {
-	APIHost            string        // API host URL, defaults to https://api.sdkwa.pro
-	IDInstance         string        // Instance ID (required)
-	APITokenInstance   string        // API token instance (required)
-	MessengerType      MessengerType // Messenger type, defaults to whatsapp
-	UserID             string        // User ID (optional, required for instance management)
-	UserToken          string        // User token (optional, required for instance management)
-	Timeout            time.Duration // HTTP client timeout, defaults to 30 seconds
-	InsecureSkipVerify bool          // Skip TLS certificate verification
+	APIHost            string           // API host URL, defaults to https://api.sdkwa.pro
+	IDInstance         string           // Instance ID (required)
+	APITokenInstance   string           // API token instance (required)
+	MessengerType      MessengerType    // Messenger type, defaults to whatsapp
+	UserID             string           // User ID (optional, required for instance management)
+	UserToken          string           // User token (optional, required for instance management)
+	Timeout            time.Duration    // HTTP client timeout, defaults to 30 seconds
+	InsecureSkipVerify bool             // Skip TLS certificate verification
+	Retry              RetryPolicy      // Retry policy for failed requests, retries are disabled by default
+	RateLimit          RateLimitOptions // Client-side rate limiting, disabled by default
+	ValidateChatIDs    bool             // Check chat IDs with ParseChatID in send, ReadChat and group methods before sending requests
+
+	// HTTPClient is used for all API requests when set; Timeout and the transport
+	// options below are then ignored
+	HTTPClient *http.Client
+	// Transport replaces the default transport when set; the proxy, TLS and
+	// connection pool options below are then ignored
+	Transport http.RoundTripper
+
+	ProxyURL            string            // Proxy for API and WebSocket connections, defaults to HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	RootCAs             *x509.CertPool    // CA certificates used to verify the API server, defaults to the system pool
+	Certificates        []tls.Certificate // Client certificates for mutual TLS
+	MaxIdleConns        int               // Maximum idle connections in total, defaults to 100
+	MaxIdleConnsPerHost int               // Maximum idle connections to the API host, defaults to 2
+	MaxConnsPerHost     int               // Maximum connections to the API host, unlimited by default
+	IdleConnTimeout     time.Duration     // Time an idle connection is kept open, defaults to 90 seconds
+
+	Middleware []ClientMiddleware // Wrap every request attempt, the first one being the outermost
+	Hooks      ClientHooks        // Observe every request attempt
+
+	// Logger receives the client's logs, including those of its WebSocket clients and
+	// pollers, defaults to slog.Default(). The API and user tokens are always redacted.
+	Logger   *slog.Logger
+	LogLevel slog.Leveler // Minimum level of the client's logs, left to the logger's handler when nil
+
+	// TracerProvider creates a client span for every API call, defaults to the global
+	// provider of otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+
+	// Metrics receives request, retry and rate limiter measurements, and those of the
+	// client's WebSocket clients and pollers. Disabled when nil.
+	Metrics Metrics
 }
 
 // NewClient creates a new SDKWA client with the provided options
@@ -78,11 +124,9 @@ func NewClient(opts Options) (*Client, error) {
 	// Remove trailing slash from API host
 	opts.APIHost = strings.TrimSuffix(opts.APIHost, "/")
 
-	// Create HTTP client with custom transport
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: opts.InsecureSkipVerify,
-		},
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	client := &Client{
@@ -93,11 +137,16 @@ func NewClient(opts Options) (*Client, error) {
 		userID:           opts.UserID,
 		userToken:        opts.UserToken,
 		basePath:         fmt.Sprintf("/%s/%s", opts.MessengerType, opts.IDInstance),
-		httpClient: &http.Client{
-			Timeout:   opts.Timeout,
-			Transport: transport,
-		},
+		httpClient:       httpClient,
+		retry:            opts.Retry.withDefaults(),
+		limiter:          newRateLimiter(opts.RateLimit),
+		hooks:            opts.Hooks,
+		logger:           newLogger(opts.Logger, opts.LogLevel, opts.APITokenInstance, opts.UserToken).With("instanceId", opts.IDInstance),
+		tracer:           newTracer(opts.TracerProvider),
+		metrics:          metricsOrNop(opts.Metrics),
+		validateChatIDs:  opts.ValidateChatIDs,
 	}
+	client.send = client.buildSender(opts.Middleware)
 
 	return client, nil
 }
@@ -116,68 +165,30 @@ func (e *ErrorResponse) Error() string {
 
 // request makes an HTTP request to the API
 func (c *Client) request(ctx context.Context, method, path string, body interface{}, result interface{}, opts ...*RequestOptions) error {
-	var bodyReader io.Reader
+	var payload []byte
 	contentType := "application/json"
 
 	if body != nil {
 		if formData, ok := body.(*bytes.Buffer); ok {
-			bodyReader = formData
+			payload = formData.Bytes()
 			contentType = "" // Will be set by multipart writer
 		} else {
 			jsonBody, err := json.Marshal(body)
 			if err != nil {
 				return fmt.Errorf("failed to marshal request body: %w", err)
 			}
-			bodyReader = bytes.NewReader(jsonBody)
+			payload = jsonBody
 		}
 	}
 
-	// Apply messenger type override if provided
-	finalPath := path
-	if len(opts) > 0 && opts[0] != nil && opts[0].MessengerType != "" {
-		// Replace messenger type in path
-		overrideBasePath := fmt.Sprintf("/%s/%s", opts[0].MessengerType, c.idInstance)
-		finalPath = strings.Replace(path, c.basePath, overrideBasePath, 1)
-	}
-
-	fullURL := c.apiHost + finalPath
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiTokenInstance)
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+c.apiTokenInstance)
 	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		var apiError ErrorResponse
-		if err := json.Unmarshal(respBody, &apiError); err != nil {
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
-		}
-		return &apiError
+		header.Set("Content-Type", contentType)
 	}
 
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-	}
-
-	return nil
+	path = c.resolvePath(path, opts...)
+	return c.do(ctx, c.newRequestInfo(method, path, body), payload, header, result)
 }
 
 // multipartRequest makes a multipart form request to the API
@@ -207,101 +218,177 @@ func (c *Client) multipartRequest(ctx context.Context, method, path string, fiel
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	// Apply messenger type override if provided
-	finalPath := path
-	if len(opts) > 0 && opts[0] != nil && opts[0].MessengerType != "" {
-		// Replace messenger type in path
-		overrideBasePath := fmt.Sprintf("/%s/%s", opts[0].MessengerType, c.idInstance)
-		finalPath = strings.Replace(path, c.basePath, overrideBasePath, 1)
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+c.apiTokenInstance)
+	header.Set("Content-Type", writer.FormDataContentType())
+
+	// The form is fully buffered so every retry attempt can resend it
+	path = c.resolvePath(path, opts...)
+	return c.do(ctx, c.newRequestInfo(method, path, fields), buf.Bytes(), header, result)
+}
+
+// requestWithUserAuth makes a request with user authentication headers
+func (c *Client) requestWithUserAuth(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	if c.userID == "" || c.userToken == "" {
+		return ErrMissingUserCredentials
 	}
 
-	fullURL := c.apiHost + finalPath
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, &buf)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	var payload []byte
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = jsonBody
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiTokenInstance)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// Set user authentication headers
+	header := make(http.Header)
+	header.Set("x-user-id", c.userID)
+	header.Set("x-user-token", c.userToken)
+	header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to make request: %w", err)
+	return c.do(ctx, c.newRequestInfo(method, path, body), payload, header, result)
+}
+
+// resolvePath applies the messenger type override from request options to an API path
+func (c *Client) resolvePath(path string, opts ...*RequestOptions) string {
+	if len(opts) > 0 && opts[0] != nil && opts[0].MessengerType != "" {
+		// Replace messenger type in path
+		overrideBasePath := fmt.Sprintf("/%s/%s", opts[0].MessengerType, c.idInstance)
+		return strings.Replace(path, c.basePath, overrideBasePath, 1)
 	}
-	defer resp.Body.Close()
+	return path
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// do sends a request to the API, retrying according to the client's retry policy,
+// and decodes a successful response into result
+func (c *Client) do(ctx context.Context, info *RequestInfo, body []byte, header http.Header, result interface{}) (err error) {
+	ctx, span := c.startRequestSpan(ctx, info)
+	var resp *http.Response
+	attempts := 0
+	defer func() {
+		endRequestSpan(span, resp, attempts, err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, info.HTTPMethod, c.apiHost+info.Path, nil)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header = header
 
-	if resp.StatusCode >= 400 {
-		var apiError ErrorResponse
-		if err := json.Unmarshal(respBody, &apiError); err != nil {
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+	for attempt := 1; ; attempt++ {
+		wait, err := c.limiter.waitGlobal(ctx)
+		if c.limiter != nil {
+			c.metrics.ObserveRateLimitWait(wait)
 		}
-		return &apiError
-	}
-
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+		if err != nil {
+			return err
 		}
-	}
 
-	return nil
-}
+		attemptInfo := *info
+		attemptInfo.Attempt = attempt
+		attempts = attempt
+
+		start := time.Now()
+		resp, err = c.doOnce(req, &attemptInfo, body, result)
+		latency := time.Since(start)
+		c.logRequest(ctx, &attemptInfo, resp, latency, err)
+		c.observeRequest(&attemptInfo, resp, latency)
+		if err == nil {
+			if c.hooks.AfterResponse != nil {
+				c.hooks.AfterResponse(resp, &attemptInfo, latency)
+			}
+			return nil
+		}
+		if c.hooks.OnError != nil {
+			c.hooks.OnError(err, &attemptInfo, latency)
+		}
+		if ctx.Err() != nil || !c.retry.shouldRetry(attempt, resp, err) {
+			return err
+		}
 
-// requestWithUserAuth makes a request with user authentication headers
-func (c *Client) requestWithUserAuth(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	if c.userID == "" || c.userToken == "" {
-		return errors.New("userID and userToken are required for this operation")
+		if err := sleepContext(ctx, c.retry.delay(attempt, resp)); err != nil {
+			return err
+		}
+		c.metrics.ObserveRetry(info.APIMethod)
 	}
+}
 
-	var bodyReader io.Reader
+// doOnce performs a single HTTP round trip. A nil response means the request failed in
+// transport; otherwise the response body has already been consumed and closed and the
+// response is only meant for inspecting the status and headers.
+func (c *Client) doOnce(req *http.Request, info *RequestInfo, body []byte, result interface{}) (*http.Response, error) {
+	// A fresh body reader per attempt lets the same payload be sent again on retry
+	req = req.Clone(req.Context())
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
 		}
-		bodyReader = bytes.NewReader(jsonBody)
+		req.ContentLength = int64(len(body))
 	}
 
-	fullURL := c.apiHost + path
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if c.hooks.BeforeRequest != nil {
+		c.hooks.BeforeRequest(req, info)
 	}
 
-	// Set user authentication headers
-	req.Header.Set("x-user-id", c.userID)
-	req.Header.Set("x-user-token", c.userToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req, info)
 	if err != nil {
-		return fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return resp, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		var apiError ErrorResponse
-		if err := json.Unmarshal(respBody, &apiError); err != nil {
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
-		}
-		return &apiError
+		return resp, newAPIError(req, resp, respBody)
 	}
 
 	if result != nil {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return resp, fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 	}
 
-	return nil
+	return resp, nil
+}
+
+// observeRequest reports a request attempt to the client's metrics
+func (c *Client) observeRequest(info *RequestInfo, resp *http.Response, latency time.Duration) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(info.APIMethod, status, latency)
+}
+
+// logRequest logs a request attempt at debug level
+func (c *Client) logRequest(ctx context.Context, info *RequestInfo, resp *http.Response, latency time.Duration, err error) {
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", info.APIMethod),
+		slog.String("httpMethod", info.HTTPMethod),
+		slog.String("path", info.Path),
+		slog.Int("attempt", info.Attempt),
+		slog.Duration("duration", latency),
+	}
+	if chatID := chatIDOf(info.Params); chatID != "" {
+		attrs = append(attrs, slog.String("chatId", chatID))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "API request failed", attrs...)
+		return
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "API request completed", attrs...)
 }
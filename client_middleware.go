@@ -0,0 +1,69 @@
+package sdkwa
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes an API request attempt made by the Client
+type RequestInfo struct {
+	APIMethod  string      // API method name taken from the path, e.g. "sendMessage"
+	HTTPMethod string      // HTTP method
+	Path       string      // Request path after the messenger type override, with query
+	Params     interface{} // Parameters passed to the API method: the JSON body value or the form fields of an upload, nil when none
+	Attempt    int         // Attempt number, starting at 1
+}
+
+// RoundTripFunc sends a single API request attempt
+type RoundTripFunc func(req *http.Request, info *RequestInfo) (*http.Response, error)
+
+// ClientMiddleware wraps the sending of every request attempt, like an http.RoundTripper.
+// It may modify the request, for example to add headers, or return its own response.
+type ClientMiddleware func(next RoundTripFunc) RoundTripFunc
+
+// ClientHooks observe every request attempt. The response passed to AfterResponse has
+// already been read, so hooks can inspect its status and headers only.
+type ClientHooks struct {
+	BeforeRequest func(req *http.Request, info *RequestInfo)                          // Called before sending, may modify the request
+	AfterResponse func(resp *http.Response, info *RequestInfo, latency time.Duration) // Called after a successful attempt
+	OnError       func(err error, info *RequestInfo, latency time.Duration)           // Called after a failed attempt, including API errors
+}
+
+// buildSender chains the client middlewares around the HTTP client, the first one
+// being the outermost
+func (c *Client) buildSender(middlewares []ClientMiddleware) RoundTripFunc {
+	send := RoundTripFunc(func(req *http.Request, info *RequestInfo) (*http.Response, error) {
+		return c.httpClient.Do(req)
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		send = middlewares[i](send)
+	}
+	return send
+}
+
+// newRequestInfo describes a request to path made with the given parameters
+func (c *Client) newRequestInfo(method, path string, params interface{}) *RequestInfo {
+	return &RequestInfo{
+		APIMethod:  c.apiMethod(path),
+		HTTPMethod: method,
+		Path:       path,
+		Params:     params,
+	}
+}
+
+// apiMethod returns the API method name of a path: the segment following the instance
+// ID in instance paths (/whatsapp/{id}/sendMessage), the last segment otherwise
+func (c *Client) apiMethod(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == c.idInstance {
+			return segments[i+1]
+		}
+	}
+	return segments[len(segments)-1]
+}
@@ -0,0 +1,74 @@
+package sdkwa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_MiddlewareAndHooks tests that middlewares and hooks see every attempt
+func TestClient_MiddlewareAndHooks(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "abc-123", r.Header.Get("X-Correlation-Id"))
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"idMessage":"MSG1"}`)
+	}))
+	defer server.Close()
+
+	var events []string
+	correlation := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request, info *RequestInfo) (*http.Response, error) {
+			req.Header.Set("X-Correlation-Id", "abc-123")
+			events = append(events, "middleware")
+			return next(req, info)
+		}
+	}
+
+	client := newTestClient(t, server, Options{
+		Retry:      RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		Middleware: []ClientMiddleware{correlation},
+		Hooks: ClientHooks{
+			BeforeRequest: func(req *http.Request, info *RequestInfo) {
+				events = append(events, fmt.Sprintf("before %s %d", info.APIMethod, info.Attempt))
+			},
+			AfterResponse: func(resp *http.Response, info *RequestInfo, latency time.Duration) {
+				events = append(events, fmt.Sprintf("after %d %d", info.Attempt, resp.StatusCode))
+			},
+			OnError: func(err error, info *RequestInfo, latency time.Duration) {
+				events = append(events, fmt.Sprintf("error %d %v", info.Attempt, err))
+				assert.Equal(t, SendMessageParams{ChatID: "1@c.us", Message: "hi"}, info.Params)
+			},
+		},
+	})
+
+	_, err := client.SendMessage(context.Background(), SendMessageParams{ChatID: "1@c.us", Message: "hi"})
+	require.NoError(t, err)
+
+	require.Len(t, events, 6)
+	assert.Equal(t, "before sendMessage 1", events[0])
+	assert.Equal(t, "middleware", events[1])
+	assert.True(t, strings.HasPrefix(events[2], "error 1 "))
+	assert.Equal(t, []string{"before sendMessage 2", "middleware", "after 2 200"}, events[3:])
+}
+
+// TestClient_APIMethod tests the method names derived from request paths
+func TestClient_APIMethod(t *testing.T) {
+	client := &Client{idInstance: "1101"}
+
+	assert.Equal(t, "sendMessage", client.apiMethod("/whatsapp/1101/sendMessage"))
+	assert.Equal(t, "deleteNotification", client.apiMethod("/whatsapp/1101/deleteNotification/42"))
+	assert.Equal(t, "receiveNotification", client.apiMethod("/telegram/1101/receiveNotification?receiveTimeout=20"))
+	assert.Equal(t, "createByOrder", client.apiMethod("/api/v1/instance/user/instance/createByOrder"))
+}
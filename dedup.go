@@ -0,0 +1,129 @@
+package sdkwa
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Deduplicator records which events have already been handled so that an event
+// delivered twice (by webhook redelivery, or by several sources at once) reaches
+// the callbacks only once. Implementations backed by a shared store make
+// deduplication work across processes.
+type Deduplicator interface {
+	// Claim marks key as handled and reports whether it was seen for the first time
+	Claim(ctx context.Context, key string) (bool, error)
+	// Release forgets key so that a redelivered event is handled again
+	Release(ctx context.Context, key string) error
+}
+
+// DedupKey returns the key identifying an event for deduplication: the instance,
+// webhook type and message ID (plus the status for outgoingMessageStatus events),
+// or the queue receipt for events without a message ID. Events with neither
+// return an empty key and are never deduplicated.
+func DedupKey(ev *WebhookEvent) string {
+	var instance int64
+	if ev.InstanceData != nil {
+		instance = ev.InstanceData.IDInstance
+	}
+
+	switch {
+	case ev.IDMessage != "" && WebhookType(ev.TypeWebhook) == WebhookTypeOutgoingMessageStatus:
+		return fmt.Sprintf("%d:%s:%s:%s", instance, ev.TypeWebhook, ev.IDMessage, ev.Status)
+	case ev.IDMessage != "":
+		return fmt.Sprintf("%d:%s:%s", instance, ev.TypeWebhook, ev.IDMessage)
+	case ev.receiptID != 0:
+		return fmt.Sprintf("%d:receipt:%d", instance, ev.receiptID)
+	default:
+		return ""
+	}
+}
+
+// MemoryDeduplicator is an in-process Deduplicator that remembers keys for a fixed TTL
+type MemoryDeduplicator struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryDeduplicator creates a MemoryDeduplicator remembering keys for ttl,
+// defaulting to 10 minutes
+func NewMemoryDeduplicator(ttl time.Duration) *MemoryDeduplicator {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+
+	return &MemoryDeduplicator{
+		ttl:       ttl,
+		expires:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Claim marks key as handled and reports whether it was seen for the first time
+func (d *MemoryDeduplicator) Claim(ctx context.Context, key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.lastSweep) >= d.ttl {
+		d.sweep(now)
+	}
+
+	if expiry, ok := d.expires[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+
+	d.expires[key] = now.Add(d.ttl)
+	return true, nil
+}
+
+// Release forgets key
+func (d *MemoryDeduplicator) Release(ctx context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.expires, key)
+	return nil
+}
+
+// sweep removes expired keys
+func (d *MemoryDeduplicator) sweep(now time.Time) {
+	for key, expiry := range d.expires {
+		if !now.Before(expiry) {
+			delete(d.expires, key)
+		}
+	}
+	d.lastSweep = now
+}
+
+// deduplicate wraps a handler so that events already claimed in dedup are skipped.
+// The claim is released when the handler fails so that a redelivery is processed.
+func deduplicate(dedup Deduplicator, next Handler) Handler {
+	return func(ctx context.Context, ev *WebhookEvent) error {
+		key := DedupKey(ev)
+		if key == "" {
+			return next(ctx, ev)
+		}
+
+		first, err := dedup.Claim(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to claim event %s: %w", key, err)
+		}
+		if !first {
+			return nil
+		}
+
+		if err := next(ctx, ev); err != nil {
+			if releaseErr := dedup.Release(context.WithoutCancel(ctx), key); releaseErr != nil {
+				return fmt.Errorf("%w (failed to release event %s: %v)", err, key, releaseErr)
+			}
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDedupKey tests the keys derived from different events
+func TestDedupKey(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"message", `{"typeWebhook":"incomingMessageReceived","instanceData":{"idInstance":7},"idMessage":"ABC"}`, "7:incomingMessageReceived:ABC"},
+		{"status", `{"typeWebhook":"outgoingMessageStatus","idMessage":"ABC","status":"read"}`, "0:outgoingMessageStatus:ABC:read"},
+		{"receipt", `{"receiptId":5,"body":{"typeWebhook":"stateInstanceChanged","stateInstance":"authorized"}}`, "0:receipt:5"},
+		{"none", stateChangedJSON, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification, err := DecodeNotification([]byte(tt.json))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, DedupKey(notification.Body))
+		})
+	}
+}
+
+// TestMemoryDeduplicator tests claiming, releasing and expiry of keys
+func TestMemoryDeduplicator(t *testing.T) {
+	ctx := context.Background()
+	dedup := NewMemoryDeduplicator(30 * time.Millisecond)
+
+	first, err := dedup.Claim(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	first, _ = dedup.Claim(ctx, "a")
+	assert.False(t, first)
+
+	require.NoError(t, dedup.Release(ctx, "a"))
+	first, _ = dedup.Claim(ctx, "a")
+	assert.True(t, first)
+
+	time.Sleep(40 * time.Millisecond)
+	first, _ = dedup.Claim(ctx, "a")
+	assert.True(t, first)
+}
+
+// TestWebhookHandler_Deduplicator tests that a redelivered event reaches callbacks once,
+// unless its first delivery failed
+func TestWebhookHandler_Deduplicator(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Deduplicator: NewMemoryDeduplicator(time.Minute)})
+	require.NoError(t, err)
+
+	calls := 0
+	fail := true
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		calls++
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	webhook := decodeMap(t, textWebhookJSON("1@c.us", "MSG1"))
+	polled := decodeMap(t, `{"receiptId":1,"body":`+textWebhookJSON("1@c.us", "MSG1")+`}`)
+
+	assert.Error(t, handler.HandleWebhook(webhook))
+	fail = false
+	require.NoError(t, handler.HandleWebhook(webhook))
+	require.NoError(t, handler.HandleWebhook(polled))
+	require.NoError(t, handler.HandleWebhook(webhook))
+
+	assert.Equal(t, 2, calls)
+}
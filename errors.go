@@ -0,0 +1,118 @@
+package sdkwa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for classifying API failures with errors.Is
+var (
+	// ErrBadRequest is matched by API errors with status 400
+	ErrBadRequest = errors.New("sdkwa: bad request")
+	// ErrUnauthorized is matched by API errors with status 401, usually an invalid API token
+	ErrUnauthorized = errors.New("sdkwa: unauthorized")
+	// ErrForbidden is matched by API errors with status 403
+	ErrForbidden = errors.New("sdkwa: forbidden")
+	// ErrNotFound is matched by API errors with status 404
+	ErrNotFound = errors.New("sdkwa: not found")
+	// ErrRateLimited is matched by API errors with status 429
+	ErrRateLimited = errors.New("sdkwa: rate limited")
+	// ErrServerError is matched by API errors with a 5xx status
+	ErrServerError = errors.New("sdkwa: server error")
+	// ErrInstanceNotAuthorized is matched by API errors reporting that the instance
+	// has not been authorized in the messenger yet (QR code or phone code pending)
+	ErrInstanceNotAuthorized = errors.New("sdkwa: instance not authorized")
+	// ErrMissingUserCredentials is returned by instance management methods when the
+	// client was created without UserID and UserToken
+	ErrMissingUserCredentials = errors.New("userID and userToken are required for this operation")
+)
+
+// APIError is returned for every API response with a status code of 400 or above
+type APIError struct {
+	StatusCode int           // HTTP status code
+	Method     string        // HTTP method of the request
+	Path       string        // Request path without the API host
+	Message    string        // Error message reported by the API, if any
+	Body       []byte        // Raw response body
+	RequestID  string        // Value of the X-Request-Id response header, if any
+	RetryAfter time.Duration // Parsed Retry-After response header, zero if absent
+
+	// Response holds the decoded error body when the API returned JSON
+	Response *ErrorResponse
+}
+
+// newAPIError builds an APIError from a failed response and its body
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Body:       body,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		apiErr.RetryAfter = retryAfter
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		if errResp.StatusCode == 0 {
+			errResp.StatusCode = resp.StatusCode
+		}
+		apiErr.Response = &errResp
+		apiErr.Message = errResp.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("API error: %s %s: HTTP %d: %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// Is reports whether the error matches one of the package's sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	case ErrInstanceNotAuthorized:
+		return isInstanceNotAuthorizedMessage(e.Message)
+	}
+	return false
+}
+
+// Unwrap exposes the decoded error body so errors.As can still extract an *ErrorResponse
+func (e *APIError) Unwrap() error {
+	if e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// isInstanceNotAuthorizedMessage reports whether an API error message says the instance is not authorized
+func isInstanceNotAuthorizedMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "notauthorized") ||
+		(strings.Contains(msg, "instance") && strings.Contains(msg, "not authorized"))
+}
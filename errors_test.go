@@ -0,0 +1,91 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPIError_Sentinels tests that API errors match the sentinel for their status code
+func TestAPIError_Sentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+	}{
+		{"bad request", http.StatusBadRequest, `{"message":"invalid chatId"}`, ErrBadRequest},
+		{"unauthorized", http.StatusUnauthorized, `{"message":"Unauthorized"}`, ErrUnauthorized},
+		{"forbidden", http.StatusForbidden, `forbidden`, ErrForbidden},
+		{"not found", http.StatusNotFound, `not found`, ErrNotFound},
+		{"rate limited", http.StatusTooManyRequests, `{"message":"too many requests"}`, ErrRateLimited},
+		{"server error", http.StatusBadGateway, `<html>bad gateway</html>`, ErrServerError},
+		{"instance not authorized", http.StatusBadRequest, `{"message":"Instance is not authorized"}`, ErrInstanceNotAuthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server, Options{})
+
+			_, err := client.SendMessage(context.Background(), SendMessageParams{ChatID: "79999999999@c.us"})
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.sentinel)
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "POST", apiErr.Method)
+			assert.Equal(t, "/whatsapp/test-instance/sendMessage", apiErr.Path)
+			assert.Equal(t, tt.body, string(apiErr.Body))
+		})
+	}
+}
+
+// TestAPIError_Details tests request ID, Retry-After and ErrorResponse extraction
+func TestAPIError_Details(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"slow down"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{})
+
+	_, err := client.GetStateInstance(context.Background())
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "req-123", apiErr.RequestID)
+	assert.Equal(t, 7*time.Second, apiErr.RetryAfter)
+	assert.Equal(t, "slow down", apiErr.Message)
+	assert.NotErrorIs(t, err, ErrServerError)
+
+	var errResp *ErrorResponse
+	require.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "slow down", errResp.Message)
+	assert.Equal(t, http.StatusTooManyRequests, errResp.StatusCode)
+}
+
+// TestRequestWithUserAuth_MissingCredentials tests the sentinel for missing user credentials
+func TestRequestWithUserAuth_MissingCredentials(t *testing.T) {
+	client, err := NewClient(Options{IDInstance: "test-instance", APITokenInstance: "test-token"})
+	require.NoError(t, err)
+
+	_, err = client.GetInstances(context.Background())
+	assert.ErrorIs(t, err, ErrMissingUserCredentials)
+}
@@ -0,0 +1,115 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// EventSource selects how Client.Events receives notifications
+type EventSource int
+
+const (
+	EventSourcePolling   EventSource = iota // Notifications queue (ReceiveNotification / DeleteNotification)
+	EventSourceWebSocket                    // WebSocketClient
+)
+
+// String returns the name of the source
+func (s EventSource) String() string {
+	switch s {
+	case EventSourcePolling:
+		return "polling"
+	case EventSourceWebSocket:
+		return "websocket"
+	default:
+		return fmt.Sprintf("EventSource(%d)", int(s))
+	}
+}
+
+// eventErrorBuffer is the capacity of the error channel returned by Client.Events
+const eventErrorBuffer = 16
+
+// EventsOptions configures Client.Events
+type EventsOptions struct {
+	BufferSize int              // Capacity of the event channel, unbuffered when zero; ignored by EventSourcePolling
+	Poller     PollerOptions    // Options of the poller used by EventSourcePolling
+	WebSocket  WebSocketOptions // Options of the WebSocket client used by EventSourceWebSocket, usually with AutoReconnect
+}
+
+// Events receives notifications from source and delivers them on the returned event
+// channel until ctx is done. Delivery blocks until the event is read, so a slow reader
+// slows down receiving. With EventSourcePolling the channel is unbuffered so that a
+// notification is acknowledged only once it has been read from the channel, unless
+// Poller.MaxInFlight above one deletes it when received. Errors are sent on the error
+// channel and dropped when it is full. Both channels are closed when the source stops,
+// after a terminal error if any.
+func (c *Client) Events(ctx context.Context, source EventSource, opts EventsOptions) (<-chan *WebhookEvent, <-chan error) {
+	bufferSize := opts.BufferSize
+	if source == EventSourcePolling {
+		// Buffered events would be acknowledged before anyone read them, and lost if the
+		// reader stopped
+		bufferSize = 0
+	}
+	events := make(chan *WebhookEvent, bufferSize)
+	errs := make(chan error, eventErrorBuffer)
+
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	handler := NewWebhookHandler()
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	var run func(ctx context.Context) error
+	switch source {
+	case EventSourcePolling:
+		pollerOpts := opts.Poller
+		userOnError := pollerOpts.OnError
+		pollerOpts.OnError = func(err error) {
+			if userOnError != nil {
+				userOnError(err)
+			}
+			if ctx.Err() == nil {
+				report(err)
+			}
+		}
+		run = c.NewPoller(handler, pollerOpts).Run
+	case EventSourceWebSocket:
+		wsOpts := opts.WebSocket
+		userOnStateChange := wsOpts.OnStateChange
+		wsOpts.OnStateChange = func(state WebSocketState, err error) {
+			if userOnStateChange != nil {
+				userOnStateChange(state, err)
+			}
+			if state == WebSocketReconnecting && err != nil {
+				report(err)
+			}
+		}
+		run = c.NewWebSocketClientWithOptions(handler, wsOpts).Run
+	default:
+		run = func(ctx context.Context) error {
+			return fmt.Errorf("unknown event source %s", source)
+		}
+	}
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		if err := run(ctx); err != nil && !errors.Is(err, ctx.Err()) {
+			report(err)
+		}
+	}()
+
+	return events, errs
+}
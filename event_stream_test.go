@@ -0,0 +1,93 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_EventsPolling tests that polled notifications are delivered on the channel
+// and that the channels close when the context ends
+func TestClient_EventsPolling(t *testing.T) {
+	queue := &queueServer{queue: []int64{1, 2, 3}}
+	server := httptest.NewServer(queue)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, errs := client.Events(ctx, EventSourcePolling, EventsOptions{
+		BufferSize: 1,
+		Poller:     PollerOptions{IdleInterval: time.Millisecond},
+	})
+
+	var ids []string
+	for ev := range events {
+		ids = append(ids, ev.IDMessage)
+		if len(ids) == 3 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, []string{"M1", "M2", "M3"}, ids)
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestClient_EventsPollingUnread tests that notifications not read from the channel stay
+// in the queue even when a buffer is requested
+func TestClient_EventsPollingUnread(t *testing.T) {
+	queue := &queueServer{queue: []int64{1, 2, 3}}
+	server := httptest.NewServer(queue)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, _ := client.Events(ctx, EventSourcePolling, EventsOptions{
+		BufferSize: 10,
+		Poller:     PollerOptions{IdleInterval: time.Millisecond, MinBackoff: time.Millisecond},
+	})
+
+	ev := <-events
+	assert.Equal(t, "M1", ev.IDMessage)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	for range events {
+	}
+
+	assert.Equal(t, 2, queue.remaining())
+}
+
+// TestClient_EventsWebSocket tests delivery from the WebSocket and the terminal error
+func TestClient_EventsWebSocket(t *testing.T) {
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(stateChangedJSON))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "bye"))
+	})
+	client := newTestClient(t, server, Options{})
+
+	events, errs := client.Events(context.Background(), EventSourceWebSocket, EventsOptions{})
+
+	var types []WebhookType
+	for ev := range events {
+		types = append(types, ev.Type())
+	}
+	assert.Equal(t, []WebhookType{WebhookTypeStateInstanceChanged}, types)
+
+	err := <-errs
+	var closeErr *WebSocketCloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+
+	_, open := <-errs
+	assert.False(t, open)
+}
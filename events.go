@@ -0,0 +1,362 @@
+package sdkwa
+
+import "context"
+
+// Typed webhook events passed to the On*Message and other typed callbacks of WebhookHandler.
+// Each event embeds the decoded WebhookEvent, so all envelope fields stay accessible.
+
+// MessageEvent is a message of any kind
+type MessageEvent struct {
+	*WebhookEvent
+	Message Message
+}
+
+// TextMessageEvent is an incoming text message
+type TextMessageEvent struct {
+	*WebhookEvent
+	Message *TextMessage
+}
+
+// ExtendedTextMessageEvent is an incoming extended text message
+type ExtendedTextMessageEvent struct {
+	*WebhookEvent
+	Message *ExtendedTextMessage
+}
+
+// ImageMessageEvent is an incoming image message
+type ImageMessageEvent struct {
+	*WebhookEvent
+	Message *ImageMessage
+}
+
+// VideoMessageEvent is an incoming video message
+type VideoMessageEvent struct {
+	*WebhookEvent
+	Message *VideoMessage
+}
+
+// AudioMessageEvent is an incoming audio message or voice note
+type AudioMessageEvent struct {
+	*WebhookEvent
+	Message *AudioMessage
+}
+
+// DocumentMessageEvent is an incoming document message
+type DocumentMessageEvent struct {
+	*WebhookEvent
+	Message *DocumentMessage
+}
+
+// StickerMessageEvent is an incoming sticker message
+type StickerMessageEvent struct {
+	*WebhookEvent
+	Message *StickerMessage
+}
+
+// LocationMessageEvent is an incoming location message
+type LocationMessageEvent struct {
+	*WebhookEvent
+	Message *LocationMessage
+}
+
+// ContactMessageEvent is an incoming contact card message
+type ContactMessageEvent struct {
+	*WebhookEvent
+	Message *ContactMessage
+}
+
+// ContactsArrayMessageEvent is an incoming message with several contact cards
+type ContactsArrayMessageEvent struct {
+	*WebhookEvent
+	Message *ContactsArrayMessage
+}
+
+// ReactionMessageEvent is an incoming reaction
+type ReactionMessageEvent struct {
+	*WebhookEvent
+	Message *ReactionMessage
+}
+
+// PollMessageEvent is an incoming poll
+type PollMessageEvent struct {
+	*WebhookEvent
+	Message *PollMessage
+}
+
+// PollUpdateMessageEvent is an update of the votes of a poll
+type PollUpdateMessageEvent struct {
+	*WebhookEvent
+	Message *PollUpdateMessage
+}
+
+// QuotedMessageEvent is an incoming reply to another message
+type QuotedMessageEvent struct {
+	*WebhookEvent
+	Message *QuotedMessage
+}
+
+// OutgoingStatusEvent reports the delivery status of a sent message
+// (sent, delivered, read, failed, noAccount, notInGroup)
+type OutgoingStatusEvent struct {
+	*WebhookEvent
+}
+
+// StateChangedEvent reports a change of the instance authorization state
+type StateChangedEvent struct {
+	*WebhookEvent
+	State string // authorized, notAuthorized, blocked, sleepMode, starting, yellowCard
+}
+
+// StatusInstanceChangedEvent reports the instance socket going online or offline
+type StatusInstanceChangedEvent struct {
+	*WebhookEvent
+	Status string // online or offline
+}
+
+// DeviceInfoEvent reports information about the connected phone
+type DeviceInfoEvent struct {
+	*WebhookEvent
+}
+
+// IncomingCallEvent reports an incoming call
+type IncomingCallEvent struct {
+	*WebhookEvent
+}
+
+// IncomingBlockEvent reports a chat being blocked or unblocked
+type IncomingBlockEvent struct {
+	*WebhookEvent
+}
+
+// MediaMessageEvent is an incoming media message of any kind
+type MediaMessageEvent struct {
+	*WebhookEvent
+	Kind  MessageType      // imageMessage, videoMessage, audioMessage, documentMessage or stickerMessage
+	Media *FileMessageData // Download URL, MIME type, file name, caption, size and thumbnail
+}
+
+// onTyped registers a typed handler for the given webhook type
+func (w *WebhookHandler) onTyped(webhookType WebhookType, handler Handler) {
+	w.On(handler, webhookType)
+}
+
+// OnMediaMessage registers a single callback for incoming images, videos, audio and
+// voice notes, documents and stickers. It runs in addition to the per-kind callbacks
+// such as OnImageMessage.
+func (w *WebhookHandler) OnMediaMessage(callback func(ctx context.Context, ev *MediaMessageEvent) error) {
+	handler := func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(MediaMessage); ok {
+			return callback(ctx, &MediaMessageEvent{WebhookEvent: ev, Kind: msg.MessageType(), Media: msg.Media()})
+		}
+		return nil
+	}
+
+	types := make([]WebhookType, 0, len(mediaMessageTypes))
+	for _, messageType := range mediaMessageTypes {
+		types = append(types, incomingMessageType(messageType))
+	}
+	w.On(handler, types...)
+}
+
+// OnIncomingMessage registers a callback for incoming messages of any kind
+func (w *WebhookHandler) OnIncomingMessage(callback func(ctx context.Context, ev *MessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceived, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &MessageEvent{WebhookEvent: ev, Message: ev.Message()})
+	})
+}
+
+// OnOutgoingMessage registers a callback for messages sent from the phone
+func (w *WebhookHandler) OnOutgoingMessage(callback func(ctx context.Context, ev *MessageEvent) error) {
+	w.onTyped(WebhookTypeOutgoingMessageReceived, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &MessageEvent{WebhookEvent: ev, Message: ev.Message()})
+	})
+}
+
+// OnOutgoingAPIMessage registers a callback for messages sent through the API
+func (w *WebhookHandler) OnOutgoingAPIMessage(callback func(ctx context.Context, ev *MessageEvent) error) {
+	w.onTyped(WebhookTypeOutgoingAPIMessageReceived, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &MessageEvent{WebhookEvent: ev, Message: ev.Message()})
+	})
+}
+
+// OnTextMessage registers a callback for incoming text messages
+func (w *WebhookHandler) OnTextMessage(callback func(ctx context.Context, ev *TextMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedTextMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*TextMessage); ok {
+			return callback(ctx, &TextMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnExtendedTextMessage registers a callback for incoming extended text messages
+func (w *WebhookHandler) OnExtendedTextMessage(callback func(ctx context.Context, ev *ExtendedTextMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedExtendedTextMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*ExtendedTextMessage); ok {
+			return callback(ctx, &ExtendedTextMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnImageMessage registers a callback for incoming image messages
+func (w *WebhookHandler) OnImageMessage(callback func(ctx context.Context, ev *ImageMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedImageMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*ImageMessage); ok {
+			return callback(ctx, &ImageMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnVideoMessage registers a callback for incoming video messages
+func (w *WebhookHandler) OnVideoMessage(callback func(ctx context.Context, ev *VideoMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedVideoMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*VideoMessage); ok {
+			return callback(ctx, &VideoMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnAudioMessage registers a callback for incoming audio messages and voice notes
+func (w *WebhookHandler) OnAudioMessage(callback func(ctx context.Context, ev *AudioMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedAudioMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*AudioMessage); ok {
+			return callback(ctx, &AudioMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnDocumentMessage registers a callback for incoming document messages
+func (w *WebhookHandler) OnDocumentMessage(callback func(ctx context.Context, ev *DocumentMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedDocumentMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*DocumentMessage); ok {
+			return callback(ctx, &DocumentMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnStickerMessage registers a callback for incoming sticker messages
+func (w *WebhookHandler) OnStickerMessage(callback func(ctx context.Context, ev *StickerMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedStickerMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*StickerMessage); ok {
+			return callback(ctx, &StickerMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnLocationMessage registers a callback for incoming location messages
+func (w *WebhookHandler) OnLocationMessage(callback func(ctx context.Context, ev *LocationMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedLocationMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*LocationMessage); ok {
+			return callback(ctx, &LocationMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnContactMessage registers a callback for incoming contact card messages
+func (w *WebhookHandler) OnContactMessage(callback func(ctx context.Context, ev *ContactMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedContactMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*ContactMessage); ok {
+			return callback(ctx, &ContactMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnContactsArrayMessage registers a callback for incoming messages with several contact cards
+func (w *WebhookHandler) OnContactsArrayMessage(callback func(ctx context.Context, ev *ContactsArrayMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedContactsArrayMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*ContactsArrayMessage); ok {
+			return callback(ctx, &ContactsArrayMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnReactionMessage registers a callback for incoming reactions
+func (w *WebhookHandler) OnReactionMessage(callback func(ctx context.Context, ev *ReactionMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedReactionMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*ReactionMessage); ok {
+			return callback(ctx, &ReactionMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnPollMessage registers a callback for incoming polls
+func (w *WebhookHandler) OnPollMessage(callback func(ctx context.Context, ev *PollMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedPollMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*PollMessage); ok {
+			return callback(ctx, &PollMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnPollUpdateMessage registers a callback for poll vote updates
+func (w *WebhookHandler) OnPollUpdateMessage(callback func(ctx context.Context, ev *PollUpdateMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedPollUpdateMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*PollUpdateMessage); ok {
+			return callback(ctx, &PollUpdateMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnQuotedMessage registers a callback for incoming replies to other messages
+func (w *WebhookHandler) OnQuotedMessage(callback func(ctx context.Context, ev *QuotedMessageEvent) error) {
+	w.onTyped(WebhookTypeIncomingMessageReceivedQuotedMessage, func(ctx context.Context, ev *WebhookEvent) error {
+		if msg, ok := ev.Message().(*QuotedMessage); ok {
+			return callback(ctx, &QuotedMessageEvent{WebhookEvent: ev, Message: msg})
+		}
+		return nil
+	})
+}
+
+// OnOutgoingStatus registers a callback for delivery status updates of sent messages
+func (w *WebhookHandler) OnOutgoingStatus(callback func(ctx context.Context, ev *OutgoingStatusEvent) error) {
+	w.onTyped(WebhookTypeOutgoingMessageStatus, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &OutgoingStatusEvent{WebhookEvent: ev})
+	})
+}
+
+// OnStateChanged registers a callback for instance authorization state changes
+func (w *WebhookHandler) OnStateChanged(callback func(ctx context.Context, ev *StateChangedEvent) error) {
+	w.onTyped(WebhookTypeStateInstanceChanged, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &StateChangedEvent{WebhookEvent: ev, State: ev.StateInstance})
+	})
+}
+
+// OnStatusInstanceChanged registers a callback for the instance going online or offline
+func (w *WebhookHandler) OnStatusInstanceChanged(callback func(ctx context.Context, ev *StatusInstanceChangedEvent) error) {
+	w.onTyped(WebhookTypeStatusInstanceChanged, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &StatusInstanceChangedEvent{WebhookEvent: ev, Status: ev.StatusInstance})
+	})
+}
+
+// OnDeviceInfoEvent registers a callback for device information events
+func (w *WebhookHandler) OnDeviceInfoEvent(callback func(ctx context.Context, ev *DeviceInfoEvent) error) {
+	w.onTyped(WebhookTypeDeviceInfo, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &DeviceInfoEvent{WebhookEvent: ev})
+	})
+}
+
+// OnIncomingCall registers a callback for incoming calls
+func (w *WebhookHandler) OnIncomingCall(callback func(ctx context.Context, ev *IncomingCallEvent) error) {
+	w.onTyped(WebhookTypeIncomingCall, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &IncomingCallEvent{WebhookEvent: ev})
+	})
+}
+
+// OnIncomingBlock registers a callback for chats being blocked or unblocked
+func (w *WebhookHandler) OnIncomingBlock(callback func(ctx context.Context, ev *IncomingBlockEvent) error) {
+	w.onTyped(WebhookTypeIncomingBlock, func(ctx context.Context, ev *WebhookEvent) error {
+		return callback(ctx, &IncomingBlockEvent{WebhookEvent: ev})
+	})
+}
@@ -0,0 +1,156 @@
+package sdkwa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeMap decodes a JSON object for passing to HandleWebhook
+func decodeMap(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &m))
+	return m
+}
+
+// TestWebhookHandler_TypedCallbacks tests dispatch of typed callbacks by event type
+func TestWebhookHandler_TypedCallbacks(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var text *TextMessageEvent
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		text = ev
+		return nil
+	})
+
+	var video *VideoMessageEvent
+	handler.OnVideoMessage(func(ctx context.Context, ev *VideoMessageEvent) error {
+		video = ev
+		return nil
+	})
+
+	var status *OutgoingStatusEvent
+	handler.OnOutgoingStatus(func(ctx context.Context, ev *OutgoingStatusEvent) error {
+		status = ev
+		return nil
+	})
+
+	var outgoing []*MessageEvent
+	handler.OnOutgoingAPIMessage(func(ctx context.Context, ev *MessageEvent) error {
+		outgoing = append(outgoing, ev)
+		return nil
+	})
+
+	var state *StateChangedEvent
+	handler.OnStateChanged(func(ctx context.Context, ev *StateChangedEvent) error {
+		state = ev
+		return nil
+	})
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	require.NotNil(t, text)
+	assert.Equal(t, "Hello", text.Message.Text)
+	assert.Equal(t, "Alice", text.SenderData.SenderName)
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"incomingMessageReceived","messageData":{"typeMessage":"videoMessage","fileMessageData":{"downloadUrl":"https://x/v.mp4","mimeType":"video/mp4"}}}`)))
+	require.NotNil(t, video)
+	assert.Equal(t, "video/mp4", video.Message.MimeType)
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"outgoingMessageStatus","chatId":"79001234568@c.us","idMessage":"ID","status":"delivered","sendByApi":true}`)))
+	require.NotNil(t, status)
+	assert.Equal(t, "delivered", status.Status)
+	assert.True(t, status.SendByAPI)
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"outgoingAPIMessageReceived","messageData":{"typeMessage":"textMessage","textMessageData":{"textMessage":"sent"}}}`)))
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"outgoingAPIMessageReceived","messageData":{"typeMessage":"imageMessage","fileMessageData":{"downloadUrl":"u"}}}`)))
+	require.Len(t, outgoing, 2)
+	assert.Equal(t, "sent", outgoing[0].Message.(*TextMessage).Text)
+	assert.IsType(t, &ImageMessage{}, outgoing[1].Message)
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"stateInstanceChanged","stateInstance":"authorized"}`)))
+	require.NotNil(t, state)
+	assert.Equal(t, "authorized", state.State)
+}
+
+// TestWebhookHandler_RawAndTypedTogether tests that raw callbacks keep working next to typed ones
+func TestWebhookHandler_RawAndTypedTogether(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var raw map[string]interface{}
+	handler.OnIncomingMessageText(func(data map[string]interface{}) error {
+		raw = data
+		return nil
+	})
+
+	var anyMessage *MessageEvent
+	handler.OnIncomingMessage(func(ctx context.Context, ev *MessageEvent) error {
+		anyMessage = ev
+		return nil
+	})
+
+	body := `{"typeWebhook":"incomingMessageReceived","idMessage":"ID1","messageData":{"typeMessage":"textMessage","textMessageData":{"textMessage":"hi"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, raw)
+	assert.Equal(t, "ID1", raw["idMessage"])
+	require.NotNil(t, anyMessage)
+	assert.Equal(t, "hi", anyMessage.Message.(*TextMessage).Text)
+}
+
+// TestWebhookHandler_MediaMessages tests that every media kind reaches the file and media callbacks
+func TestWebhookHandler_MediaMessages(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var files []string
+	handler.OnIncomingMessageFile(func(data map[string]interface{}) error {
+		messageData := data["messageData"].(map[string]interface{})
+		files = append(files, messageData["typeMessage"].(string))
+		return nil
+	})
+
+	var media []*MediaMessageEvent
+	handler.OnMediaMessage(func(ctx context.Context, ev *MediaMessageEvent) error {
+		media = append(media, ev)
+		return nil
+	})
+
+	var documents int
+	handler.OnDocumentMessage(func(ctx context.Context, ev *DocumentMessageEvent) error {
+		documents++
+		return nil
+	})
+
+	kinds := []string{"imageMessage", "videoMessage", "audioMessage", "documentMessage", "stickerMessage"}
+	for _, kind := range kinds {
+		payload := `{"typeWebhook":"incomingMessageReceived","messageData":{"typeMessage":"` + kind + `","fileMessageData":{"downloadUrl":"https://x/` + kind + `","mimeType":"application/octet-stream","fileName":"f","caption":"c","fileSize":1024,"jpegThumbnail":"AQID"}}}`
+		require.NoError(t, handler.HandleWebhook(decodeMap(t, payload)))
+	}
+
+	assert.Equal(t, kinds, files)
+	require.Len(t, media, len(kinds))
+	assert.Equal(t, 1, documents)
+
+	for i, ev := range media {
+		assert.Equal(t, MessageType(kinds[i]), ev.Kind)
+		assert.True(t, ev.Kind.IsMedia())
+		assert.Equal(t, "https://x/"+kinds[i], ev.Media.DownloadURL)
+		assert.Equal(t, int64(1024), ev.Media.FileSize)
+
+		thumbnail, err := ev.Media.Thumbnail()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, thumbnail)
+	}
+
+	assert.False(t, MessageTypeText.IsMedia())
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	sdkwa "github.com/sdkwa/whatsapp-api-client-go"
 )
@@ -22,8 +23,14 @@ func main() {
 		log.Fatalf("Failed to create client: %v", err)
 	}
 
-	// Create webhook handler
-	handler := sdkwa.NewWebhookHandler()
+	// Create webhook handler. Events arrive both by webhook and by polling below,
+	// so duplicates are skipped.
+	handler, err := sdkwa.NewWebhookHandlerWithOptions(sdkwa.WebhookOptions{
+		Deduplicator: sdkwa.NewMemoryDeduplicator(time.Hour),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create webhook handler: %v", err)
+	}
 
 	// Register handlers for different event types
 	handler.OnIncomingMessageText(func(data map[string]interface{}) error {
@@ -9,6 +9,10 @@ import (
 
 // UpdateGroupName changes the name of a group chat
 func (c *Client) UpdateGroupName(ctx context.Context, groupID, groupName string, opts ...*RequestOptions) (*UpdateGroupNameResponse, error) {
+	if err := c.checkChatID("groupId", groupID, groupChat, opts); err != nil {
+		return nil, err
+	}
+
 	var result UpdateGroupNameResponse
 	params := map[string]string{
 		"groupId":   groupID,
@@ -20,6 +24,10 @@ func (c *Client) UpdateGroupName(ctx context.Context, groupID, groupName string,
 
 // GetGroupData retrieves information about a group chat
 func (c *Client) GetGroupData(ctx context.Context, groupID string, opts ...*RequestOptions) (map[string]interface{}, error) {
+	if err := c.checkChatID("groupId", groupID, groupChat, opts); err != nil {
+		return nil, err
+	}
+
 	var result map[string]interface{}
 	params := map[string]string{"groupId": groupID}
 	err := c.request(ctx, "POST", c.basePath+"/getGroupData", params, &result, opts...)
@@ -28,6 +36,10 @@ func (c *Client) GetGroupData(ctx context.Context, groupID string, opts ...*Requ
 
 // LeaveGroup allows the current account user to leave a specified group chat
 func (c *Client) LeaveGroup(ctx context.Context, groupID string, opts ...*RequestOptions) (*LeaveGroupResponse, error) {
+	if err := c.checkChatID("groupId", groupID, groupChat, opts); err != nil {
+		return nil, err
+	}
+
 	var result LeaveGroupResponse
 	params := map[string]string{"groupId": groupID}
 	err := c.request(ctx, "POST", c.basePath+"/leaveGroup", params, &result, opts...)
@@ -36,6 +48,10 @@ func (c *Client) LeaveGroup(ctx context.Context, groupID string, opts ...*Reques
 
 // SetGroupAdmin assigns administrator rights to a specified participant in a group chat
 func (c *Client) SetGroupAdmin(ctx context.Context, groupID, participantChatID string, opts ...*RequestOptions) (*SetGroupAdminResponse, error) {
+	if err := c.checkParticipant(groupID, participantChatID, opts); err != nil {
+		return nil, err
+	}
+
 	var result SetGroupAdminResponse
 	params := map[string]string{
 		"groupId":           groupID,
@@ -47,6 +63,10 @@ func (c *Client) SetGroupAdmin(ctx context.Context, groupID, participantChatID s
 
 // RemoveGroupParticipant removes a specified participant from a group chat
 func (c *Client) RemoveGroupParticipant(ctx context.Context, groupID, participantChatID string, opts ...*RequestOptions) (*RemoveGroupParticipantResponse, error) {
+	if err := c.checkParticipant(groupID, participantChatID, opts); err != nil {
+		return nil, err
+	}
+
 	var result RemoveGroupParticipantResponse
 	params := map[string]string{
 		"groupId":           groupID,
@@ -58,6 +78,10 @@ func (c *Client) RemoveGroupParticipant(ctx context.Context, groupID, participan
 
 // RemoveAdmin revokes administrator rights from a specified participant in a group chat
 func (c *Client) RemoveAdmin(ctx context.Context, groupID, participantChatID string, opts ...*RequestOptions) (*RemoveAdminResponse, error) {
+	if err := c.checkParticipant(groupID, participantChatID, opts); err != nil {
+		return nil, err
+	}
+
 	var result RemoveAdminResponse
 	params := map[string]string{
 		"groupId":           groupID,
@@ -69,6 +93,12 @@ func (c *Client) RemoveAdmin(ctx context.Context, groupID, participantChatID str
 
 // CreateGroup creates a new group chat with the specified name and participants
 func (c *Client) CreateGroup(ctx context.Context, groupName string, chatIDs []string, opts ...*RequestOptions) (*CreateGroupResponse, error) {
+	for _, chatID := range chatIDs {
+		if err := c.checkChatID("chatIds", chatID, personalChat, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	var result CreateGroupResponse
 	params := map[string]interface{}{
 		"groupName": groupName,
@@ -80,6 +110,10 @@ func (c *Client) CreateGroup(ctx context.Context, groupName string, chatIDs []st
 
 // AddGroupParticipant adds a specified participant to a group chat
 func (c *Client) AddGroupParticipant(ctx context.Context, groupID, participantChatID string, opts ...*RequestOptions) (*AddGroupParticipantResponse, error) {
+	if err := c.checkParticipant(groupID, participantChatID, opts); err != nil {
+		return nil, err
+	}
+
 	var result AddGroupParticipantResponse
 	params := map[string]string{
 		"groupId":           groupID,
@@ -91,6 +125,10 @@ func (c *Client) AddGroupParticipant(ctx context.Context, groupID, participantCh
 
 // SetGroupPicture sets a new picture for a group chat
 func (c *Client) SetGroupPicture(ctx context.Context, groupID string, file io.Reader, opts ...*RequestOptions) (*SetGroupPictureResponse, error) {
+	if err := c.checkChatID("groupId", groupID, groupChat, opts); err != nil {
+		return nil, err
+	}
+
 	fields := map[string]string{
 		"groupId": groupID,
 	}
@@ -103,6 +141,14 @@ func (c *Client) SetGroupPicture(ctx context.Context, groupID string, file io.Re
 	return &result, err
 }
 
+// checkParticipant validates the group and participant chat IDs of a participant method
+func (c *Client) checkParticipant(groupID, participantChatID string, opts []*RequestOptions) error {
+	if err := c.checkChatID("groupId", groupID, groupChat, opts); err != nil {
+		return err
+	}
+	return c.checkChatID("participantChatId", participantChatID, personalChat, opts)
+}
+
 // Response types for group methods
 
 // UpdateGroupNameResponse represents the response from updating group name
@@ -0,0 +1,100 @@
+package sdkwa
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secrets in log output
+const redacted = "[REDACTED]"
+
+// tokenParamPattern matches the token query parameter of WebSocket URLs
+var tokenParamPattern = regexp.MustCompile(`([?&]token=)[^&\s"']+`)
+
+// newLogger returns logger, or slog.Default() when nil, with every record filtered by
+// level (when set) and every occurrence of the given secrets and of token query
+// parameters redacted
+func newLogger(logger *slog.Logger, level slog.Leveler, secrets ...string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var nonEmpty []string
+	for _, secret := range secrets {
+		if secret != "" {
+			nonEmpty = append(nonEmpty, secret)
+		}
+	}
+
+	return slog.New(&redactHandler{next: logger.Handler(), level: level, secrets: nonEmpty})
+}
+
+// redactHandler is a slog.Handler removing secrets from messages and attribute values
+type redactHandler struct {
+	next    slog.Handler
+	level   slog.Leveler
+	secrets []string
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil && level < h.level.Level() {
+		return false
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	clean := slog.NewRecord(r.Time, r.Level, h.redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = h.redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(clean), level: h.level, secrets: h.secrets}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), level: h.level, secrets: h.secrets}
+}
+
+// redactAttr redacts string values, including errors and other values formatted as text
+func (h *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]interface{}, len(group))
+		for i, member := range group {
+			clean[i] = h.redactAttr(member)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindAny:
+		switch value := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, h.redact(value.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, h.redact(value.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redact replaces secrets and token query parameters in s
+func (h *redactHandler) redact(s string) string {
+	for _, secret := range h.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return tokenParamPattern.ReplaceAllString(s, "${1}"+redacted)
+}
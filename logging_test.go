@@ -0,0 +1,97 @@
+package sdkwa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLogger_Redaction tests that secrets never reach the log output
+func TestNewLogger_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(slog.New(slog.NewTextHandler(&buf, nil)), nil, "api-secret", "", "user-secret")
+
+	logger.With("token", "api-secret").Info("dialing wss://host/ws/1?token=abc123&x=1",
+		"error", errors.New("failed with user-secret"),
+		slog.Group("request", "header", "Bearer api-secret"))
+
+	out := buf.String()
+	assert.NotContains(t, out, "api-secret")
+	assert.NotContains(t, out, "user-secret")
+	assert.NotContains(t, out, "abc123")
+	assert.Contains(t, out, "token=[REDACTED]&x=1")
+	assert.Equal(t, 4, strings.Count(out, redacted))
+}
+
+// TestNewLogger_Level tests the minimum level filter
+func TestNewLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger := newLogger(base, slog.LevelWarn)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+}
+
+// TestClient_Logger tests the structured request log of the client
+func TestClient_Logger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := newTestClient(t, server, Options{
+		Logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	_, err := client.GetStateInstance(context.Background())
+	require.Error(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `"msg":"API request failed"`)
+	assert.Contains(t, out, `"instanceId":"test-instance"`)
+	assert.Contains(t, out, `"method":"getStateInstance"`)
+	assert.Contains(t, out, `"status":404`)
+	assert.NotContains(t, out, "test-token")
+	assert.NotContains(t, out, `"chatId"`)
+
+	buf.Reset()
+	_, err = client.SendMessage(context.Background(), SendMessageParams{ChatID: "79001234567@c.us", Message: "hi"})
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `"chatId":"79001234567@c.us"`)
+}
+
+// TestWebhookHandler_Logger tests that ServeHTTP failures go to the configured logger
+func TestWebhookHandler_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{
+		AuthToken: "hook-secret",
+		Logger:    slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+	require.NoError(t, err)
+	handler.OnStateChanged(func(ctx context.Context, ev *StateChangedEvent) error {
+		return errors.New("rejected hook-secret")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(stateChangedJSON))
+	req.Header.Set("Authorization", "Bearer hook-secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, buf.String(), `"msg":"webhook handling failed"`)
+	assert.Contains(t, buf.String(), `"type":"stateInstanceChanged"`)
+	assert.Contains(t, buf.String(), `"error":"rejected [REDACTED]"`)
+}
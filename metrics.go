@@ -0,0 +1,50 @@
+package sdkwa
+
+import (
+	"time"
+)
+
+// Metrics receives measurements from the client, its WebSocket clients and pollers,
+// and webhook handlers. Implementations must be safe for concurrent use; the
+// prommetrics subpackage provides one backed by Prometheus.
+type Metrics interface {
+	// EventObserver receives the type, processing time and outcome of every event
+	// handled by a WebhookHandler
+	EventObserver
+
+	// ObserveRequest is called after every API request attempt with the API method,
+	// the HTTP status code (0 when the request failed in transport) and its latency
+	ObserveRequest(method string, status int, duration time.Duration)
+	// ObserveRetry is called before an API request is retried
+	ObserveRetry(method string)
+	// ObserveRateLimitWait is called with the time a request waited for the client
+	// rate limiter, when rate limiting is enabled
+	ObserveRateLimitWait(wait time.Duration)
+	// ObserveEventReceived is called when a WebhookHandler receives an event, with its
+	// source: "webhook", "websocket", "polling", or "" when passed to HandleEvent directly
+	ObserveEventReceived(webhookType WebhookType, source string)
+	// ObserveWebSocketReconnect is called when a WebSocket client schedules a reconnection
+	ObserveWebSocketReconnect()
+	// ObservePollLag is called with the age of every notification taken from the queue
+	// by a Poller
+	ObservePollLag(lag time.Duration)
+}
+
+// nopMetrics is the Metrics used when none is configured
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveEvent(WebhookType, time.Duration, error) {}
+func (nopMetrics) ObserveRequest(string, int, time.Duration)      {}
+func (nopMetrics) ObserveRetry(string)                            {}
+func (nopMetrics) ObserveRateLimitWait(time.Duration)             {}
+func (nopMetrics) ObserveEventReceived(WebhookType, string)       {}
+func (nopMetrics) ObserveWebSocketReconnect()                     {}
+func (nopMetrics) ObservePollLag(time.Duration)                   {}
+
+// metricsOrNop returns m, or a Metrics discarding measurements when nil
+func metricsOrNop(m Metrics) Metrics {
+	if m == nil {
+		return nopMetrics{}
+	}
+	return m
+}
@@ -0,0 +1,103 @@
+package sdkwa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics records the measurements it receives
+type recordingMetrics struct {
+	mu       sync.Mutex
+	events   []string
+	pollLags []time.Duration
+}
+
+func (m *recordingMetrics) record(format string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, fmt.Sprintf(format, args...))
+}
+
+func (m *recordingMetrics) ObserveEvent(webhookType WebhookType, duration time.Duration, err error) {
+	m.record("handled %s %v", webhookType, err)
+}
+
+func (m *recordingMetrics) ObserveRequest(method string, status int, duration time.Duration) {
+	m.record("request %s %d", method, status)
+}
+
+func (m *recordingMetrics) ObserveRetry(method string) {
+	m.record("retry %s", method)
+}
+
+func (m *recordingMetrics) ObserveRateLimitWait(wait time.Duration) {
+	m.record("wait")
+}
+
+func (m *recordingMetrics) ObserveEventReceived(webhookType WebhookType, source string) {
+	m.record("received %s %s", webhookType, source)
+}
+
+func (m *recordingMetrics) ObserveWebSocketReconnect() {
+	m.record("reconnect")
+}
+
+func (m *recordingMetrics) ObservePollLag(lag time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pollLags = append(m.pollLags, lag)
+}
+
+// TestMetrics_Polling tests the measurements of a poller and its client
+func TestMetrics_Polling(t *testing.T) {
+	received := time.Now().Add(-time.Minute).Unix()
+	var mu sync.Mutex
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/receiveNotification") && !deleted:
+			fmt.Fprintf(w, `{"receiptId":1,"body":{"typeWebhook":"incomingMessageReceived","idMessage":"M1","timestamp":%d,"messageData":{"typeMessage":"textMessage"}}}`, received)
+		case strings.HasSuffix(r.URL.Path, "/receiveNotification"):
+			fmt.Fprint(w, "null")
+		default:
+			deleted = true
+			fmt.Fprint(w, `{"result":true}`)
+		}
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := newTestClient(t, server, Options{Metrics: metrics})
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{Metrics: metrics})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error {
+		cancel()
+		return nil
+	})
+
+	err = client.NewPoller(handler, PollerOptions{IdleInterval: time.Hour}).Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, []string{
+		"request receiveNotification 200",
+		"received incomingMessageReceived_textMessage polling",
+		"handled incomingMessageReceived_textMessage <nil>",
+		"request deleteNotification 200",
+	}, metrics.events)
+	require.Len(t, metrics.pollLags, 1)
+	assert.GreaterOrEqual(t, metrics.pollLags[0], time.Minute)
+}
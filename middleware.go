@@ -0,0 +1,128 @@
+package sdkwa
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware wraps a Handler to add behavior around event processing
+type Middleware func(next Handler) Handler
+
+// Use appends middlewares to the handler. They apply to events from every source
+// (ServeHTTP, WebSocketClient and notification polling) and run in the order given,
+// the first one being the outermost.
+func (w *WebhookHandler) Use(middlewares ...Middleware) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.middlewares = append(w.middlewares, middlewares...)
+}
+
+// PanicError is returned by RecoverMiddleware, and by TimeoutMiddleware, when a handler panics
+type PanicError struct {
+	Value interface{} // Value passed to panic
+	Stack []byte      // Stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("webhook handler panic: %v", e.Value)
+}
+
+// RecoverMiddleware converts panics in handlers into a *PanicError
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev *WebhookEvent) error {
+			return callRecovering(ctx, ev, next)
+		}
+	}
+}
+
+// callRecovering calls a handler, converting a panic into a *PanicError
+func callRecovering(ctx context.Context, ev *WebhookEvent, handler Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return handler(ctx, ev)
+}
+
+// LoggingMiddleware logs every event with its type, message ID, chat, duration and outcome.
+// A nil logger uses slog.Default().
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev *WebhookEvent) error {
+			start := time.Now()
+			err := next(ctx, ev)
+
+			attrs := append(eventAttrs(ev), slog.Duration("duration", time.Since(start)))
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(ctx, slog.LevelError, "webhook event failed", attrs...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelDebug, "webhook event handled", attrs...)
+			}
+
+			return err
+		}
+	}
+}
+
+// eventAttrs returns the log attributes identifying an event
+func eventAttrs(ev *WebhookEvent) []slog.Attr {
+	return []slog.Attr{
+		slog.String("type", string(ev.Type())),
+		slog.String("idMessage", ev.IDMessage),
+		slog.String("chatId", ev.Chat()),
+	}
+}
+
+// TimeoutMiddleware limits the time spent handling a single event. The handler's context
+// is cancelled after d and the context error is returned; a handler that ignores its
+// context keeps running in the background until it returns. The handler runs on its own
+// goroutine, so its panics are returned as a *PanicError rather than propagated to the
+// middlewares wrapping this one.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev *WebhookEvent) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- callRecovering(ctx, ev, next)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return fmt.Errorf("webhook handler timed out after %s: %w", d, ctx.Err())
+			}
+		}
+	}
+}
+
+// EventObserver receives the outcome of every handled event, e.g. to record metrics
+type EventObserver interface {
+	ObserveEvent(webhookType WebhookType, duration time.Duration, err error)
+}
+
+// MetricsMiddleware reports the type, processing time and outcome of every event to observer
+func MetricsMiddleware(observer EventObserver) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev *WebhookEvent) error {
+			start := time.Now()
+			err := next(ctx, ev)
+			observer.ObserveEvent(ev.Type(), time.Since(start), err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package sdkwa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookHandler_UseOrder tests that middlewares wrap dispatch in the order given
+func TestWebhookHandler_UseOrder(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, ev *WebhookEvent) error {
+				order = append(order, name+":before")
+				err := next(ctx, ev)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+
+	handler.Use(trace("outer"), trace("inner"))
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	assert.Equal(t, []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}, order)
+}
+
+// TestRecoverMiddleware tests that panics become errors
+func TestRecoverMiddleware(t *testing.T) {
+	handler := NewWebhookHandler()
+	handler.Use(RecoverMiddleware())
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		panic("kaboom")
+	})
+
+	err := handler.HandleWebhook(decodeMap(t, textNotificationJSON))
+
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "kaboom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+}
+
+// TestTimeoutMiddleware tests that slow handlers are cut off
+func TestTimeoutMiddleware(t *testing.T) {
+	handler := NewWebhookHandler()
+	handler.Use(TimeoutMiddleware(20 * time.Millisecond))
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := handler.HandleWebhook(decodeMap(t, textNotificationJSON))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestRecoverAndTimeoutMiddleware tests that panics under a timeout reach an outer RecoverMiddleware as errors
+func TestRecoverAndTimeoutMiddleware(t *testing.T) {
+	handler := NewWebhookHandler()
+	handler.Use(RecoverMiddleware(), TimeoutMiddleware(time.Second))
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		panic("boom")
+	})
+
+	err := handler.HandleWebhook(decodeMap(t, textNotificationJSON))
+
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "boom", panicErr.Value)
+}
+
+// TestLoggingMiddleware tests structured log output for failed events
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := NewWebhookHandler()
+	handler.Use(LoggingMiddleware(logger))
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		return errors.New("handler failed")
+	})
+
+	assert.Error(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	assert.Contains(t, buf.String(), `"type":"incomingMessageReceived_textMessage"`)
+	assert.Contains(t, buf.String(), `"chatId":"79001234568@c.us"`)
+	assert.Contains(t, buf.String(), `"error":"handler failed"`)
+}
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	types  []WebhookType
+	errors []error
+}
+
+func (o *recordingObserver) ObserveEvent(webhookType WebhookType, duration time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.types = append(o.types, webhookType)
+	o.errors = append(o.errors, err)
+}
+
+// TestMetricsMiddleware tests that every event is reported to the observer
+func TestMetricsMiddleware(t *testing.T) {
+	observer := &recordingObserver{}
+
+	handler := NewWebhookHandler()
+	handler.Use(MetricsMiddleware(observer))
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"stateInstanceChanged"}`)))
+
+	assert.Equal(t, []WebhookType{WebhookTypeIncomingMessageReceivedTextMessage, WebhookTypeStateInstanceChanged}, observer.types)
+	assert.Equal(t, []error{nil, nil}, observer.errors)
+}
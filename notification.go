@@ -0,0 +1,656 @@
+package sdkwa
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Notification is an entry of the incoming notification queue
+type Notification struct {
+	ReceiptID int64         `json:"receiptId"`
+	Body      *WebhookEvent `json:"body"`
+}
+
+// WebhookEvent is the payload of a webhook, WebSocket message or queued notification.
+// Which fields are set depends on TypeWebhook; top-level members not described here are
+// kept in Extra. Nested objects other than messageData and quotedMessage drop unknown
+// members, but the original payload is kept and returned unchanged by Raw, so no data
+// is lost when the API adds new fields.
+type WebhookEvent struct {
+	TypeWebhook  string        `json:"typeWebhook"`
+	InstanceData *InstanceData `json:"instanceData,omitempty"`
+	Timestamp    int64         `json:"timestamp,omitempty"`
+	IDMessage    string        `json:"idMessage,omitempty"`
+	SenderData   *SenderData   `json:"senderData,omitempty"`
+	MessageData  *MessageData  `json:"messageData,omitempty"`
+
+	StateInstance  string      `json:"stateInstance,omitempty"`  // stateInstanceChanged
+	StatusInstance string      `json:"statusInstance,omitempty"` // statusInstanceChanged
+	ChatID         string      `json:"chatId,omitempty"`         // outgoingMessageStatus, incomingBlock
+	Status         string      `json:"status,omitempty"`         // outgoingMessageStatus, incomingCall
+	Description    string      `json:"description,omitempty"`    // outgoingMessageStatus
+	SendByAPI      bool        `json:"sendByApi,omitempty"`      // outgoingMessageStatus
+	From           string      `json:"from,omitempty"`           // incomingCall
+	StatusBlock    string      `json:"statusBlock,omitempty"`    // incomingBlock
+	DeviceData     *DeviceData `json:"deviceData,omitempty"`     // deviceInfo
+
+	// Extra holds members of the payload that are not mapped to a field
+	Extra map[string]json.RawMessage `json:"-"`
+
+	raw       map[string]interface{} // original untyped payload, if the event was parsed from one
+	payload   json.RawMessage        // original encoded payload, if the event was decoded from one
+	receiptID int64                  // receipt of a notification taken from the queue
+	ack       func(ctx context.Context) error
+}
+
+// InstanceData identifies the instance that produced an event
+type InstanceData struct {
+	IDInstance   int64  `json:"idInstance"`
+	Wid          string `json:"wid"`
+	TypeInstance string `json:"typeInstance"`
+}
+
+// SenderData describes the chat and sender of a message event
+type SenderData struct {
+	ChatID            string `json:"chatId"`
+	ChatName          string `json:"chatName,omitempty"`
+	Sender            string `json:"sender"`
+	SenderName        string `json:"senderName,omitempty"`
+	SenderContactName string `json:"senderContactName,omitempty"`
+}
+
+// DeviceData describes the phone connected to the instance
+type DeviceData struct {
+	Platform           string `json:"platform,omitempty"`
+	DeviceManufacturer string `json:"deviceManufacturer,omitempty"`
+	DeviceModel        string `json:"deviceModel,omitempty"`
+	OSVersion          string `json:"osVersion,omitempty"`
+	WAVersion          string `json:"waVersion,omitempty"`
+	Battery            int    `json:"battery,omitempty"`
+}
+
+// Type returns the webhook type used for callback dispatch, combining typeWebhook
+// and typeMessage for message events (e.g. incomingMessageReceived_textMessage)
+func (e *WebhookEvent) Type() WebhookType {
+	if e.MessageData != nil && e.MessageData.TypeMessage != "" {
+		return WebhookType(fmt.Sprintf("%s_%s", e.TypeWebhook, e.MessageData.TypeMessage))
+	}
+	return WebhookType(e.TypeWebhook)
+}
+
+// Time returns the event timestamp
+func (e *WebhookEvent) Time() time.Time {
+	return time.Unix(e.Timestamp, 0)
+}
+
+// Chat returns the chat the event belongs to, or an empty string if it is not chat related
+func (e *WebhookEvent) Chat() string {
+	if e.SenderData != nil && e.SenderData.ChatID != "" {
+		return e.SenderData.ChatID
+	}
+	return e.ChatID
+}
+
+// Message returns the typed message of a message event, or nil for other events
+func (e *WebhookEvent) Message() Message {
+	if e.MessageData == nil {
+		return nil
+	}
+	return e.MessageData.Message()
+}
+
+// Raw returns the event as an untyped map, as passed to WebhookCallback. Events parsed
+// or decoded from a payload return it unchanged, including members the typed fields
+// do not describe; events built in code are converted from their fields.
+func (e *WebhookEvent) Raw() map[string]interface{} {
+	if e.raw != nil {
+		return e.raw
+	}
+
+	data := make(map[string]interface{})
+	if e.payload != nil {
+		if err := json.Unmarshal(e.payload, &data); err == nil {
+			return data
+		}
+	}
+	if encoded, err := json.Marshal(e); err == nil {
+		_ = json.Unmarshal(encoded, &data)
+	}
+	return data
+}
+
+// ReceiptID returns the queue receipt of an event received by polling, or zero
+// for events delivered by webhook or WebSocket
+func (e *WebhookEvent) ReceiptID() int64 {
+	return e.receiptID
+}
+
+// UnmarshalJSON decodes the event and keeps unknown members in Extra
+func (e *WebhookEvent) UnmarshalJSON(data []byte) error {
+	type plain WebhookEvent
+	extra, err := decodeExtra(data, (*plain)(e))
+	if err != nil {
+		return err
+	}
+	e.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the event including the members kept in Extra
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	type plain WebhookEvent
+	return encodeExtra((*plain)(&e), e.Extra)
+}
+
+// MessageType is the typeMessage of a message event
+type MessageType string
+
+const (
+	MessageTypeText          MessageType = "textMessage"
+	MessageTypeExtendedText  MessageType = "extendedTextMessage"
+	MessageTypeImage         MessageType = "imageMessage"
+	MessageTypeVideo         MessageType = "videoMessage"
+	MessageTypeAudio         MessageType = "audioMessage"
+	MessageTypeDocument      MessageType = "documentMessage"
+	MessageTypeSticker       MessageType = "stickerMessage"
+	MessageTypeLocation      MessageType = "locationMessage"
+	MessageTypeContact       MessageType = "contactMessage"
+	MessageTypeContactsArray MessageType = "contactsArrayMessage"
+	MessageTypeReaction      MessageType = "reactionMessage"
+	MessageTypePoll          MessageType = "pollMessage"
+	MessageTypePollUpdate    MessageType = "pollUpdateMessage"
+	MessageTypeQuoted        MessageType = "quotedMessage"
+)
+
+// MessageData is the messageData member of a message event. Exactly one of the
+// *Data fields is normally set, matching TypeMessage; use Message to get it as a typed value.
+type MessageData struct {
+	TypeMessage             MessageType              `json:"typeMessage"`
+	TextMessageData         *TextMessageData         `json:"textMessageData,omitempty"`
+	ExtendedTextMessageData *ExtendedTextMessageData `json:"extendedTextMessageData,omitempty"`
+	FileMessageData         *FileMessageData         `json:"fileMessageData,omitempty"`
+	LocationMessageData     *LocationMessageData     `json:"locationMessageData,omitempty"`
+	ContactMessageData      *ContactMessageData      `json:"contactMessageData,omitempty"`
+	PollMessageData         *PollMessageData         `json:"pollMessageData,omitempty"`
+	QuotedMessage           *Quote                   `json:"quotedMessage,omitempty"`
+
+	// ContactsArrayMessageData is sent under a nested messageData member by the API
+	ContactsArrayMessageData *ContactsArrayMessageData `json:"messageData,omitempty"`
+
+	// Extra holds members of messageData that are not mapped to a field
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the message data and keeps unknown members in Extra
+func (m *MessageData) UnmarshalJSON(data []byte) error {
+	type plain MessageData
+	extra, err := decodeExtra(data, (*plain)(m))
+	if err != nil {
+		return err
+	}
+	m.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the message data including the members kept in Extra
+func (m MessageData) MarshalJSON() ([]byte, error) {
+	type plain MessageData
+	return encodeExtra((*plain)(&m), m.Extra)
+}
+
+// Message returns the message as one of the concrete Message types, or *UnknownMessage
+// if the type is not known to this version of the library or its data is missing
+func (m *MessageData) Message() Message {
+	switch m.TypeMessage {
+	case MessageTypeText:
+		if m.TextMessageData != nil {
+			return &TextMessage{Text: m.TextMessageData.TextMessage}
+		}
+	case MessageTypeExtendedText:
+		if m.ExtendedTextMessageData != nil {
+			return &ExtendedTextMessage{ExtendedTextMessageData: *m.ExtendedTextMessageData}
+		}
+	case MessageTypeImage:
+		if m.FileMessageData != nil {
+			return &ImageMessage{FileMessageData: *m.FileMessageData}
+		}
+	case MessageTypeVideo:
+		if m.FileMessageData != nil {
+			return &VideoMessage{FileMessageData: *m.FileMessageData}
+		}
+	case MessageTypeAudio:
+		if m.FileMessageData != nil {
+			return &AudioMessage{FileMessageData: *m.FileMessageData}
+		}
+	case MessageTypeDocument:
+		if m.FileMessageData != nil {
+			return &DocumentMessage{FileMessageData: *m.FileMessageData}
+		}
+	case MessageTypeSticker:
+		if m.FileMessageData != nil {
+			return &StickerMessage{FileMessageData: *m.FileMessageData}
+		}
+	case MessageTypeLocation:
+		if m.LocationMessageData != nil {
+			return &LocationMessage{LocationMessageData: *m.LocationMessageData}
+		}
+	case MessageTypeContact:
+		if m.ContactMessageData != nil {
+			return &ContactMessage{ContactMessageData: *m.ContactMessageData}
+		}
+	case MessageTypeContactsArray:
+		if m.ContactsArrayMessageData != nil {
+			return &ContactsArrayMessage{Contacts: m.ContactsArrayMessageData.Contacts}
+		}
+	case MessageTypeReaction:
+		if m.ExtendedTextMessageData != nil {
+			return &ReactionMessage{Reaction: m.ExtendedTextMessageData.Text, Quote: m.QuotedMessage}
+		}
+	case MessageTypePoll:
+		if m.PollMessageData != nil {
+			return &PollMessage{PollMessageData: *m.PollMessageData}
+		}
+	case MessageTypePollUpdate:
+		if m.PollMessageData != nil {
+			return &PollUpdateMessage{PollMessageData: *m.PollMessageData}
+		}
+	case MessageTypeQuoted:
+		if m.ExtendedTextMessageData != nil {
+			return &QuotedMessage{Text: m.ExtendedTextMessageData.Text, Quote: m.QuotedMessage}
+		}
+	}
+
+	return &UnknownMessage{Type: m.TypeMessage, Data: m}
+}
+
+// Wire types of the message data members
+
+// TextMessageData is the textMessageData member of a text message
+type TextMessageData struct {
+	TextMessage string `json:"textMessage"`
+}
+
+// ExtendedTextMessageData is the extendedTextMessageData member of extended text, reaction and quoted messages
+type ExtendedTextMessageData struct {
+	Text            string `json:"text"`
+	Description     string `json:"description,omitempty"`
+	Title           string `json:"title,omitempty"`
+	PreviewType     string `json:"previewType,omitempty"`
+	JPEGThumbnail   string `json:"jpegThumbnail,omitempty"`
+	ForwardingScore int    `json:"forwardingScore,omitempty"`
+	IsForwarded     bool   `json:"isForwarded,omitempty"`
+	StanzaID        string `json:"stanzaId,omitempty"`
+	Participant     string `json:"participant,omitempty"`
+}
+
+// FileMessageData is the fileMessageData member of image, video, audio, document and sticker messages
+type FileMessageData struct {
+	DownloadURL     string `json:"downloadUrl"`
+	Caption         string `json:"caption,omitempty"`
+	FileName        string `json:"fileName,omitempty"`
+	FileSize        int64  `json:"fileSize,omitempty"` // Size in bytes, when reported by the API
+	JPEGThumbnail   string `json:"jpegThumbnail,omitempty"`
+	MimeType        string `json:"mimeType,omitempty"`
+	IsAnimated      bool   `json:"isAnimated,omitempty"`
+	ForwardingScore int    `json:"forwardingScore,omitempty"`
+	IsForwarded     bool   `json:"isForwarded,omitempty"`
+}
+
+// Thumbnail returns the decoded JPEG preview of the file, or nil if there is none
+func (f *FileMessageData) Thumbnail() ([]byte, error) {
+	if f.JPEGThumbnail == "" {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(f.JPEGThumbnail)
+}
+
+// LocationMessageData is the locationMessageData member of a location message
+type LocationMessageData struct {
+	NameLocation  string  `json:"nameLocation,omitempty"`
+	Address       string  `json:"address,omitempty"`
+	JPEGThumbnail string  `json:"jpegThumbnail,omitempty"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+}
+
+// ContactMessageData is the contactMessageData member of a contact message
+type ContactMessageData struct {
+	DisplayName string `json:"displayName"`
+	VCard       string `json:"vcard"`
+}
+
+// ContactsArrayMessageData is the data of a message carrying several contacts
+type ContactsArrayMessageData struct {
+	Contacts []ContactMessageData `json:"contacts"`
+}
+
+// PollMessageData is the pollMessageData member of poll and poll update messages
+type PollMessageData struct {
+	StanzaID        string       `json:"stanzaId,omitempty"`
+	Name            string       `json:"name"`
+	Options         []PollOption `json:"options,omitempty"`
+	Votes           []PollVote   `json:"votes,omitempty"`
+	MultipleAnswers bool         `json:"multipleAnswers"`
+}
+
+// PollOption is an answer option of a poll
+type PollOption struct {
+	OptionName string `json:"optionName"`
+}
+
+// PollVote lists the voters of a poll option
+type PollVote struct {
+	OptionName   string   `json:"optionName"`
+	OptionVoters []string `json:"optionVoters"`
+}
+
+// Quote references the message that a reply or reaction refers to
+type Quote struct {
+	StanzaID    string      `json:"stanzaId"`
+	Participant string      `json:"participant,omitempty"`
+	TypeMessage MessageType `json:"typeMessage,omitempty"`
+	TextMessage string      `json:"textMessage,omitempty"`
+
+	// Extra holds members of quotedMessage that are not mapped to a field
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the quote and keeps unknown members in Extra
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type plain Quote
+	extra, err := decodeExtra(data, (*plain)(q))
+	if err != nil {
+		return err
+	}
+	q.Extra = extra
+	return nil
+}
+
+// MarshalJSON encodes the quote including the members kept in Extra
+func (q Quote) MarshalJSON() ([]byte, error) {
+	type plain Quote
+	return encodeExtra((*plain)(&q), q.Extra)
+}
+
+// Message kinds
+
+// Message is implemented by all typed message kinds returned by MessageData.Message
+type Message interface {
+	MessageType() MessageType
+}
+
+// MediaMessage is implemented by the message kinds that carry a downloadable file
+type MediaMessage interface {
+	Message
+	Media() *FileMessageData
+}
+
+// mediaMessageTypes lists the message kinds that implement MediaMessage
+var mediaMessageTypes = []MessageType{
+	MessageTypeImage,
+	MessageTypeVideo,
+	MessageTypeAudio,
+	MessageTypeDocument,
+	MessageTypeSticker,
+}
+
+// IsMedia reports whether messages of this type carry a downloadable file
+func (t MessageType) IsMedia() bool {
+	for _, mediaType := range mediaMessageTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// TextMessage is a plain text message
+type TextMessage struct {
+	Text string
+}
+
+// ExtendedTextMessage is a text message with a link preview or forwarding information
+type ExtendedTextMessage struct {
+	ExtendedTextMessageData
+}
+
+// ImageMessage is an image file message
+type ImageMessage struct {
+	FileMessageData
+}
+
+// VideoMessage is a video file message
+type VideoMessage struct {
+	FileMessageData
+}
+
+// AudioMessage is an audio file or voice note message
+type AudioMessage struct {
+	FileMessageData
+}
+
+// DocumentMessage is a document file message
+type DocumentMessage struct {
+	FileMessageData
+}
+
+// StickerMessage is a sticker message
+type StickerMessage struct {
+	FileMessageData
+}
+
+// LocationMessage is a location message
+type LocationMessage struct {
+	LocationMessageData
+}
+
+// ContactMessage is a single contact card message
+type ContactMessage struct {
+	ContactMessageData
+}
+
+// ContactsArrayMessage is a message carrying several contact cards
+type ContactsArrayMessage struct {
+	Contacts []ContactMessageData
+}
+
+// ReactionMessage is an emoji reaction to another message
+type ReactionMessage struct {
+	Reaction string // Reaction emoji, empty when a reaction was removed
+	Quote    *Quote // Message the reaction refers to
+}
+
+// PollMessage is a newly created poll
+type PollMessage struct {
+	PollMessageData
+}
+
+// PollUpdateMessage carries the current votes of a poll
+type PollUpdateMessage struct {
+	PollMessageData
+}
+
+// QuotedMessage is a text reply to another message
+type QuotedMessage struct {
+	Text  string // Reply text
+	Quote *Quote // Message being replied to
+}
+
+// UnknownMessage is returned for message types this version of the library does not model
+type UnknownMessage struct {
+	Type MessageType
+	Data *MessageData // Complete message data including Extra
+}
+
+func (*TextMessage) MessageType() MessageType          { return MessageTypeText }
+func (*ExtendedTextMessage) MessageType() MessageType  { return MessageTypeExtendedText }
+func (*ImageMessage) MessageType() MessageType         { return MessageTypeImage }
+func (*VideoMessage) MessageType() MessageType         { return MessageTypeVideo }
+func (*AudioMessage) MessageType() MessageType         { return MessageTypeAudio }
+func (*DocumentMessage) MessageType() MessageType      { return MessageTypeDocument }
+func (*StickerMessage) MessageType() MessageType       { return MessageTypeSticker }
+func (*LocationMessage) MessageType() MessageType      { return MessageTypeLocation }
+func (*ContactMessage) MessageType() MessageType       { return MessageTypeContact }
+func (*ContactsArrayMessage) MessageType() MessageType { return MessageTypeContactsArray }
+func (*ReactionMessage) MessageType() MessageType      { return MessageTypeReaction }
+func (*PollMessage) MessageType() MessageType          { return MessageTypePoll }
+func (*PollUpdateMessage) MessageType() MessageType    { return MessageTypePollUpdate }
+func (*QuotedMessage) MessageType() MessageType        { return MessageTypeQuoted }
+func (m *UnknownMessage) MessageType() MessageType     { return m.Type }
+
+func (m *ImageMessage) Media() *FileMessageData    { return &m.FileMessageData }
+func (m *VideoMessage) Media() *FileMessageData    { return &m.FileMessageData }
+func (m *AudioMessage) Media() *FileMessageData    { return &m.FileMessageData }
+func (m *DocumentMessage) Media() *FileMessageData { return &m.FileMessageData }
+func (m *StickerMessage) Media() *FileMessageData  { return &m.FileMessageData }
+
+// Decoding
+
+// DecodeNotification decodes a queued notification ({"receiptId": ..., "body": {...}})
+// or a bare event as delivered by webhooks and WebSocket. For bare events the
+// returned ReceiptID is zero. The event body is kept as received for WebhookEvent.Raw.
+func DecodeNotification(data []byte) (*Notification, error) {
+	var envelope struct {
+		ReceiptID *int64          `json:"receiptId"`
+		Body      json.RawMessage `json:"body"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode notification: %w", err)
+	}
+
+	notification := &Notification{}
+	payload := data
+	if envelope.ReceiptID != nil && len(envelope.Body) > 0 {
+		notification.ReceiptID = *envelope.ReceiptID
+		payload = envelope.Body
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode notification body: %w", err)
+	}
+	if event.TypeWebhook == "" {
+		return nil, errors.New("failed to decode notification: missing typeWebhook")
+	}
+	event.receiptID = notification.ReceiptID
+	event.payload = append(json.RawMessage(nil), payload...)
+	notification.Body = &event
+
+	return notification, nil
+}
+
+// ParseWebhookEvent converts an untyped notification, as passed to WebhookCallback,
+// into a WebhookEvent. Queued notifications wrapped in a body member are unwrapped.
+func ParseWebhookEvent(data map[string]interface{}) (*WebhookEvent, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	notification, err := DecodeNotification(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	notification.Body.raw = data
+	if body, ok := data["body"].(map[string]interface{}); ok {
+		if _, ok := data["receiptId"]; ok {
+			notification.Body.raw = body
+		}
+	}
+	return notification.Body, nil
+}
+
+// ReceiveTypedNotification retrieves a single incoming notification from the notifications
+// queue as a typed Notification. It returns nil without error when the queue is empty.
+func (c *Client) ReceiveTypedNotification(ctx context.Context, opts ...*RequestOptions) (*Notification, error) {
+	var result json.RawMessage
+	if err := c.request(ctx, "GET", c.basePath+"/receiveNotification", nil, &result, opts...); err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(result)) == 0 || bytes.Equal(bytes.TrimSpace(result), []byte("null")) {
+		return nil, nil
+	}
+	return DecodeNotification(result)
+}
+
+// decodeExtra unmarshals data into v, a pointer to a struct, and returns the object
+// members that do not correspond to any of the struct's JSON fields
+func decodeExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	for name := range members {
+		for _, field := range known {
+			// encoding/json matches member names case-insensitively
+			if strings.EqualFold(name, field) {
+				delete(members, name)
+				break
+			}
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// encodeExtra marshals v and adds the extra members that v does not already contain
+func encodeExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, exists := members[name]; !exists {
+			members[name] = value
+		}
+	}
+
+	return json.Marshal(members)
+}
+
+// jsonFieldNames returns the JSON member names of a struct type's exported fields
+func jsonFieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,203 @@
+package sdkwa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textNotificationJSON = `{
+	"receiptId": 42,
+	"body": {
+		"typeWebhook": "incomingMessageReceived",
+		"instanceData": {"idInstance": 1101000001, "wid": "79001234567@c.us", "typeInstance": "whatsapp"},
+		"timestamp": 1588091580,
+		"idMessage": "F7AEC1B7086ECDC7E6E45923F5EDB825",
+		"senderData": {"chatId": "79001234568@c.us", "sender": "79001234568@c.us", "senderName": "Alice"},
+		"messageData": {
+			"typeMessage": "textMessage",
+			"textMessageData": {"textMessage": "Hello"}
+		}
+	}
+}`
+
+// TestDecodeNotification_Text tests decoding of a queued text notification
+func TestDecodeNotification_Text(t *testing.T) {
+	notification, err := DecodeNotification([]byte(textNotificationJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), notification.ReceiptID)
+	ev := notification.Body
+	require.NotNil(t, ev)
+	assert.Equal(t, WebhookTypeIncomingMessageReceivedTextMessage, ev.Type())
+	assert.Equal(t, int64(1101000001), ev.InstanceData.IDInstance)
+	assert.Equal(t, "79001234568@c.us", ev.Chat())
+	assert.Equal(t, int64(1588091580), ev.Time().Unix())
+
+	msg, ok := ev.Message().(*TextMessage)
+	require.True(t, ok)
+	assert.Equal(t, "Hello", msg.Text)
+}
+
+// TestMessageData_Kinds tests the discriminated union for each message kind
+func TestMessageData_Kinds(t *testing.T) {
+	tests := []struct {
+		name        string
+		messageData string
+		check       func(t *testing.T, msg Message)
+	}{
+		{
+			name:        "image",
+			messageData: `{"typeMessage":"imageMessage","fileMessageData":{"downloadUrl":"https://x/y.jpg","caption":"pic","mimeType":"image/jpeg"}}`,
+			check: func(t *testing.T, msg Message) {
+				image := msg.(*ImageMessage)
+				assert.Equal(t, "https://x/y.jpg", image.DownloadURL)
+				assert.Equal(t, "pic", image.Caption)
+			},
+		},
+		{
+			name:        "document",
+			messageData: `{"typeMessage":"documentMessage","fileMessageData":{"downloadUrl":"https://x/a.pdf","fileName":"a.pdf"}}`,
+			check: func(t *testing.T, msg Message) {
+				assert.Equal(t, "a.pdf", msg.(*DocumentMessage).FileName)
+			},
+		},
+		{
+			name:        "location",
+			messageData: `{"typeMessage":"locationMessage","locationMessageData":{"latitude":55.7,"longitude":37.6,"nameLocation":"Moscow"}}`,
+			check: func(t *testing.T, msg Message) {
+				location := msg.(*LocationMessage)
+				assert.Equal(t, 55.7, location.Latitude)
+				assert.Equal(t, "Moscow", location.NameLocation)
+			},
+		},
+		{
+			name:        "contacts array",
+			messageData: `{"typeMessage":"contactsArrayMessage","messageData":{"contacts":[{"displayName":"A","vcard":"BEGIN:VCARD"},{"displayName":"B","vcard":"BEGIN:VCARD"}]}}`,
+			check: func(t *testing.T, msg Message) {
+				assert.Len(t, msg.(*ContactsArrayMessage).Contacts, 2)
+			},
+		},
+		{
+			name:        "reaction",
+			messageData: `{"typeMessage":"reactionMessage","extendedTextMessageData":{"text":"👍"},"quotedMessage":{"stanzaId":"ABC","participant":"79001234568@c.us","typeMessage":"textMessage"}}`,
+			check: func(t *testing.T, msg Message) {
+				reaction := msg.(*ReactionMessage)
+				assert.Equal(t, "👍", reaction.Reaction)
+				assert.Equal(t, "ABC", reaction.Quote.StanzaID)
+			},
+		},
+		{
+			name:        "quoted",
+			messageData: `{"typeMessage":"quotedMessage","extendedTextMessageData":{"text":"reply"},"quotedMessage":{"stanzaId":"ABC","typeMessage":"textMessage","textMessage":"original"}}`,
+			check: func(t *testing.T, msg Message) {
+				quoted := msg.(*QuotedMessage)
+				assert.Equal(t, "reply", quoted.Text)
+				assert.Equal(t, "original", quoted.Quote.TextMessage)
+			},
+		},
+		{
+			name:        "poll",
+			messageData: `{"typeMessage":"pollMessage","pollMessageData":{"name":"Lunch?","options":[{"optionName":"Yes"},{"optionName":"No"}],"multipleAnswers":false}}`,
+			check: func(t *testing.T, msg Message) {
+				poll := msg.(*PollMessage)
+				assert.Equal(t, "Lunch?", poll.Name)
+				assert.Len(t, poll.Options, 2)
+			},
+		},
+		{
+			name:        "unknown",
+			messageData: `{"typeMessage":"buttonsMessage","buttonsMessage":{"contentText":"Pick"}}`,
+			check: func(t *testing.T, msg Message) {
+				unknown := msg.(*UnknownMessage)
+				assert.Equal(t, MessageType("buttonsMessage"), unknown.MessageType())
+				assert.Contains(t, unknown.Data.Extra, "buttonsMessage")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data MessageData
+			require.NoError(t, json.Unmarshal([]byte(tt.messageData), &data))
+			tt.check(t, data.Message())
+		})
+	}
+}
+
+// TestWebhookEvent_UnknownFieldsRoundTrip tests that unknown members survive decoding and encoding
+func TestWebhookEvent_UnknownFieldsRoundTrip(t *testing.T) {
+	input := `{"typeWebhook":"outgoingMessageStatus","chatId":"79001234568@c.us","idMessage":"ID","status":"read","newField":{"a":1}}`
+
+	notification, err := DecodeNotification([]byte(input))
+	require.NoError(t, err)
+	ev := notification.Body
+	assert.Equal(t, int64(0), notification.ReceiptID)
+	assert.Equal(t, "read", ev.Status)
+	assert.JSONEq(t, `{"a":1}`, string(ev.Extra["newField"]))
+	assert.Nil(t, ev.Message())
+
+	output, err := json.Marshal(ev)
+	require.NoError(t, err)
+	assert.JSONEq(t, input, string(output))
+}
+
+// TestDecodeNotification_NestedUnknownFields tests that unknown members of nested objects
+// without Extra are still available from the raw payload
+func TestDecodeNotification_NestedUnknownFields(t *testing.T) {
+	input := `{"receiptId":1,"body":{"typeWebhook":"incomingMessageReceived",` +
+		`"senderData":{"chatId":"1@c.us","sender":"1@c.us","senderPhoneNumber":79001234567},` +
+		`"messageData":{"typeMessage":"locationMessage","locationMessageData":{"latitude":1.5,"longitude":2.5,"accuracy":10}}}}`
+
+	notification, err := DecodeNotification([]byte(input))
+	require.NoError(t, err)
+	ev := notification.Body
+	assert.Equal(t, "1@c.us", ev.Chat())
+
+	raw := ev.Raw()
+	assert.Equal(t, float64(79001234567), raw["senderData"].(map[string]interface{})["senderPhoneNumber"])
+	location := raw["messageData"].(map[string]interface{})["locationMessageData"].(map[string]interface{})
+	assert.Equal(t, float64(10), location["accuracy"])
+}
+
+// TestParseWebhookEvent tests conversion of untyped notifications
+func TestParseWebhookEvent(t *testing.T) {
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(textNotificationJSON), &data))
+
+	ev, err := ParseWebhookEvent(data)
+	require.NoError(t, err)
+	assert.Equal(t, "F7AEC1B7086ECDC7E6E45923F5EDB825", ev.IDMessage)
+
+	_, err = ParseWebhookEvent(map[string]interface{}{"foo": "bar"})
+	assert.Error(t, err)
+}
+
+// TestReceiveTypedNotification tests typed polling including the empty queue
+func TestReceiveTypedNotification(t *testing.T) {
+	empty := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if empty {
+			_, _ = w.Write([]byte(`null`))
+			return
+		}
+		_, _ = w.Write([]byte(textNotificationJSON))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{})
+
+	notification, err := client.ReceiveTypedNotification(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), notification.ReceiptID)
+
+	empty = true
+	notification, err = client.ReceiveTypedNotification(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, notification)
+}
@@ -0,0 +1,27 @@
+package sdkwa
+
+import (
+	"github.com/sdkwa/whatsapp-api-client-go/phone"
+)
+
+// PhoneNumber parses a human-formatted phone number, such as "+7 (999) 123-45-67" or
+// "8 999 123 45 67" with default region "RU", into the int64 form taken by
+// CheckWhatsApp, GetAuthorizationCode, SendConfirmationCode and contact messages.
+// See phone.Parse for the accepted formats.
+func PhoneNumber(s, defaultRegion string) (int64, error) {
+	n, err := phone.Parse(s, defaultRegion)
+	if err != nil {
+		return 0, err
+	}
+	return n.Int64(), nil
+}
+
+// PhoneChat parses a human-formatted phone number into the chat ID of its personal
+// WhatsApp chat
+func PhoneChat(s, defaultRegion string) (ChatID, error) {
+	n, err := phone.Parse(s, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return ChatID(n.ChatID()), nil
+}
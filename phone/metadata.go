@@ -0,0 +1,86 @@
+package phone
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed metadata.csv
+var metadataCSV string
+
+// region holds the numbering rules of a country or territory
+type region struct {
+	name      string   // ISO 3166-1 alpha-2 code
+	code      int      // Country calling code
+	prefix    string   // National (trunk) prefix dialled before national numbers, if any
+	minLength int      // Minimum length of national significant numbers
+	maxLength int      // Maximum length of national significant numbers
+	leading   []string // Leading digits of its numbers, for regions sharing a calling code
+}
+
+// validLength reports whether n is a valid national number length in the region
+func (r *region) validLength(n int) bool {
+	return n >= r.minLength && n <= r.maxLength
+}
+
+// regions and codes index the embedded metadata by region and by calling code, the
+// regions of a code being kept in file order
+var regions, codes = loadMetadata(metadataCSV)
+
+// loadMetadata parses the metadata table. The table is part of the package, so a
+// malformed table is a programming error.
+func loadMetadata(data string) (map[string]*region, map[int][]*region) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 6
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("phone: invalid metadata: %v", err))
+	}
+
+	byName := make(map[string]*region, len(records))
+	byCode := make(map[int][]*region)
+	for _, record := range records {
+		r := &region{name: record[0], prefix: record[2]}
+		fields := []*int{&r.code, &r.minLength, &r.maxLength}
+		for i, field := range []string{record[1], record[3], record[4]} {
+			if *fields[i], err = strconv.Atoi(field); err != nil {
+				panic(fmt.Sprintf("phone: invalid metadata for %s: %v", r.name, err))
+			}
+		}
+		if record[5] != "" {
+			r.leading = strings.Split(record[5], "|")
+		}
+
+		byName[r.name] = r
+		byCode[r.code] = append(byCode[r.code], r)
+	}
+	return byName, byCode
+}
+
+// regionFor returns the region of a national number among those sharing its calling
+// code, and whether it was chosen by its leading digits rather than by default
+func regionFor(code int, national string) (*region, bool) {
+	candidates := codes[code]
+	for _, r := range candidates {
+		for _, leading := range r.leading {
+			if strings.HasPrefix(national, leading) {
+				return r, true
+			}
+		}
+	}
+	return candidates[0], false
+}
+
+// CallingCode returns the country calling code of a region
+func CallingCode(regionCode string) (int, bool) {
+	r, ok := regions[strings.ToUpper(regionCode)]
+	if !ok {
+		return 0, false
+	}
+	return r.code, true
+}
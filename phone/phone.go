@@ -0,0 +1,147 @@
+// Package phone normalizes human-formatted phone numbers, such as "+7 (999) 123-45-67"
+// or "8 999 123 45 67", into the international forms taken by the SDKWA API. It works
+// offline from country metadata embedded in the package: calling codes, national
+// prefixes and the lengths of national numbers.
+//
+// The metadata covers every country calling code assigned to a country or territory.
+// Non-geographic codes, such as +800 or +882, are not supported. Numbers are validated
+// by length only, so a number of the right length is accepted even if it is not
+// assigned.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidNumber is matched by every error returned by Parse
+var ErrInvalidNumber = errors.New("phone: invalid phone number")
+
+// Number is a validated phone number
+type Number struct {
+	CountryCode int    // Country calling code, e.g. 7
+	National    string // National significant number, without national prefix, e.g. "9991234567"
+	Region      string // ISO 3166-1 alpha-2 region code, e.g. "RU"
+}
+
+// Parse parses a phone number. Spaces, dashes, dots, slashes and parentheses are ignored.
+// Numbers starting with + or 00 are international; other numbers are read in
+// defaultRegion (an ISO 3166-1 alpha-2 code such as "RU"), with or without its national
+// prefix or calling code. Without a default region, every number is read as international.
+func Parse(s, defaultRegion string) (Number, error) {
+	digits, international, err := clean(s)
+	if err != nil {
+		return Number{}, err
+	}
+
+	if international || defaultRegion == "" {
+		return parseInternational(s, digits)
+	}
+
+	region, ok := regions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return Number{}, fmt.Errorf("%w %q: unknown region %q", ErrInvalidNumber, s, defaultRegion)
+	}
+
+	national := digits
+	if region.prefix != "" && strings.HasPrefix(national, region.prefix) && region.validLength(len(national)-len(region.prefix)) {
+		national = national[len(region.prefix):]
+	}
+	if region.validLength(len(national)) {
+		return newNumber(region.code, national, region), nil
+	}
+
+	// Numbers written with the calling code but without +, e.g. 79991234567
+	callingCode := strconv.Itoa(region.code)
+	if strings.HasPrefix(digits, callingCode) && region.validLength(len(digits)-len(callingCode)) {
+		return newNumber(region.code, digits[len(callingCode):], region), nil
+	}
+
+	return Number{}, fmt.Errorf("%w %q: wrong length for region %s", ErrInvalidNumber, s, region.name)
+}
+
+// E164 returns the number in E.164 format, e.g. "+79991234567"
+func (n Number) E164() string {
+	return "+" + n.digits()
+}
+
+// Int64 returns the number as an integer with its calling code, e.g. 79991234567, the
+// form taken by CheckWhatsApp, GetAuthorizationCode and contact messages
+func (n Number) Int64() int64 {
+	v, _ := strconv.ParseInt(n.digits(), 10, 64)
+	return v
+}
+
+// ChatID returns the WhatsApp chat ID of the number, e.g. "79991234567@c.us"
+func (n Number) ChatID() string {
+	return n.digits() + "@c.us"
+}
+
+// String returns the number in E.164 format
+func (n Number) String() string {
+	return n.E164()
+}
+
+// digits returns the calling code followed by the national number
+func (n Number) digits() string {
+	return strconv.Itoa(n.CountryCode) + n.National
+}
+
+// clean removes formatting characters from s and reports whether it starts with an
+// international prefix (+ or 00), which is removed as well
+func clean(s string) (string, bool, error) {
+	trimmed := strings.TrimSpace(s)
+	international := strings.HasPrefix(trimmed, "+")
+	trimmed = strings.TrimPrefix(trimmed, "+")
+
+	var digits strings.Builder
+	for _, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" \u00a0-.()/", r):
+		default:
+			return "", false, fmt.Errorf("%w %q: unexpected character %q", ErrInvalidNumber, s, r)
+		}
+	}
+
+	result := digits.String()
+	if !international && strings.HasPrefix(result, "00") {
+		international = true
+		result = result[2:]
+	}
+	if result == "" {
+		return "", false, fmt.Errorf("%w %q: no digits", ErrInvalidNumber, s)
+	}
+	return result, international, nil
+}
+
+// parseInternational parses digits starting with a calling code
+func parseInternational(s, digits string) (Number, error) {
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		code, _ := strconv.Atoi(digits[:n])
+		if _, ok := codes[code]; !ok {
+			continue
+		}
+
+		national := digits[n:]
+		region, _ := regionFor(code, national)
+		if !region.validLength(len(national)) {
+			return Number{}, fmt.Errorf("%w %q: wrong length for calling code +%d", ErrInvalidNumber, s, code)
+		}
+		return newNumber(code, national, nil), nil
+	}
+	return Number{}, fmt.Errorf("%w %q: unknown calling code", ErrInvalidNumber, s)
+}
+
+// newNumber returns a number in the region its leading digits belong to, or else in
+// the default region when it shares the calling code
+func newNumber(code int, national string, defaultRegion *region) Number {
+	region, matched := regionFor(code, national)
+	if !matched && defaultRegion != nil && defaultRegion.code == code {
+		region = defaultRegion
+	}
+	return Number{CountryCode: code, National: national, Region: region.name}
+}
@@ -0,0 +1,87 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests parsing of international and national numbers
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		region string
+		e164   string
+		want   string // expected region
+	}{
+		{"+7 (999) 123-45-67", "", "+79991234567", "RU"},
+		{"8 999 123 45 67", "RU", "+79991234567", "RU"},
+		{"79991234567", "RU", "+79991234567", "RU"},
+		{"79991234567", "", "+79991234567", "RU"},
+		{"+7 701 123 4567", "", "+77011234567", "KZ"},
+		{"8 (701) 123-45-67", "RU", "+77011234567", "KZ"},
+		{"0044 20 7946 0958", "", "+442079460958", "GB"},
+		{"020 7946 0958", "gb", "+442079460958", "GB"},
+		{"(202) 555-0123", "US", "+12025550123", "US"},
+		{"1-416-555-0123", "CA", "+14165550123", "CA"},
+		{"06 12 34 56 78", "FR", "+33612345678", "FR"},
+		{"0171 1234567", "DE", "+491711234567", "DE"},
+		{"+55 11 91234-5678", "", "+5511912345678", "BR"},
+		{"050 123 4567", "UA", "+380501234567", "UA"},
+		{"+971 50 123 4567", "RU", "+971501234567", "AE"},
+		{"+964 770 123 4567", "", "+9647701234567", "IQ"},
+		{"+977 984-1234567", "", "+9779841234567", "NP"},
+		{"+237 6 71 23 45 67", "", "+237671234567", "CM"},
+		{"+352 621 123 456", "", "+352621123456", "LU"},
+		{"099 123 4567", "EC", "+593991234567", "EC"},
+		{"+1 876 555 0123", "", "+18765550123", "JM"},
+		{"07797 123456", "JE", "+447797123456", "JE"},
+		{"+44 7797 123456", "GB", "+447797123456", "JE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := Parse(tt.input, tt.region)
+			require.NoError(t, err)
+			assert.Equal(t, tt.e164, n.E164())
+			assert.Equal(t, tt.want, n.Region)
+		})
+	}
+}
+
+// TestParse_Invalid tests that malformed numbers are rejected
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		input  string
+		region string
+	}{
+		{"", "RU"},
+		{"+7 999 123", ""},
+		{"+7 999 123 45 67 8", ""},
+		{"999 123", "RU"},
+		{"+999 123 456 789", ""},
+		{"+800 1234 5678", ""},
+		{"+7 999 CALL-ME", ""},
+		{"999 123 45 67", "XX"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input, tt.region)
+		assert.ErrorIs(t, err, ErrInvalidNumber, tt.input)
+	}
+}
+
+// TestNumber_Forms tests the conversions of a number
+func TestNumber_Forms(t *testing.T) {
+	n, err := Parse("+7 (999) 123-45-67", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, n.CountryCode)
+	assert.Equal(t, "9991234567", n.National)
+	assert.Equal(t, int64(79991234567), n.Int64())
+	assert.Equal(t, "79991234567@c.us", n.ChatID())
+	assert.Equal(t, "+79991234567", n.String())
+
+	code, ok := CallingCode("ae")
+	assert.True(t, ok)
+	assert.Equal(t, 971, code)
+}
@@ -0,0 +1,24 @@
+package sdkwa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdkwa/whatsapp-api-client-go/phone"
+)
+
+// TestPhoneHelpers tests parsing human-formatted phone numbers into API forms
+func TestPhoneHelpers(t *testing.T) {
+	number, err := PhoneNumber("8 (999) 123-45-67", "RU")
+	require.NoError(t, err)
+	assert.Equal(t, int64(79991234567), number)
+
+	chat, err := PhoneChat("+44 20 7946 0958", "")
+	require.NoError(t, err)
+	assert.Equal(t, ChatID("442079460958@c.us"), chat)
+
+	_, err = PhoneChat("12345", "")
+	assert.ErrorIs(t, err, phone.ErrInvalidNumber)
+}
@@ -0,0 +1,413 @@
+package sdkwa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AckPolicy decides when a Poller deletes a notification from the queue. A notification
+// that is not deleted is received and handled again after a backoff delay.
+type AckPolicy int
+
+const (
+	// AckAfterAttempts deletes a notification once it is handled successfully or has
+	// failed MaxAttempts times, passing it to DeadLetter in the latter case
+	AckAfterAttempts AckPolicy = iota
+	// AckOnSuccess deletes a notification only once it is handled successfully
+	AckOnSuccess
+	// AckAlways deletes every notification after handling it, even when handling failed
+	AckAlways
+	// AckManual deletes a notification only when the handler calls WebhookEvent.Ack
+	AckManual
+)
+
+// PollerOptions configures how a Poller reads the notifications queue
+type PollerOptions struct {
+	IdleInterval   time.Duration // Wait after finding the queue empty without long polling, defaults to 5s
+	ReceiveTimeout time.Duration // Server-side wait for a notification (long polling), whole seconds, disabled when zero; keep it below Options.Timeout
+	MinBackoff     time.Duration // Wait after the first failed receive, doubling on each further failure, defaults to 1s
+	MaxBackoff     time.Duration // Maximum wait between failed receives, defaults to 1m
+
+	// MaxInFlight is the number of notifications handled concurrently, defaults to 1.
+	// The queue only returns the next notification once the current one is deleted, so
+	// with more than one each notification is deleted as soon as it is received: delivery
+	// becomes at-most-once, AckPolicy and MaxAttempts are ignored, notifications whose
+	// handling failed go to DeadLetter, and events may be handled out of order.
+	MaxInFlight int
+
+	AckPolicy   AckPolicy // When notifications are deleted from the queue, defaults to AckAfterAttempts
+	MaxAttempts int       // Handling attempts before AckAfterAttempts gives up on a notification, defaults to 5
+
+	// DeadLetter receives notifications that AckAfterAttempts gives up on, with the
+	// last handler error, before they are deleted, as well as failed notifications
+	// already deleted because MaxInFlight is above one. They are logged when unset.
+	DeadLetter func(ctx context.Context, notification *Notification, err error)
+
+	// OnError receives errors of receiving, decoding, handling and deleting
+	// notifications. The poller keeps running; errors are logged when unset.
+	OnError func(err error)
+}
+
+// withDefaults returns a copy of the options with zero values replaced by defaults
+func (o PollerOptions) withDefaults() PollerOptions {
+	if o.IdleInterval <= 0 {
+		o.IdleInterval = 5 * time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Minute
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = o.MinBackoff
+	}
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = 1
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	return o
+}
+
+// Poller receives notifications from the queue and passes them to a WebhookHandler.
+// The queue is drained without pauses; the poller waits only when it is empty.
+type Poller struct {
+	client  *Client
+	handler *WebhookHandler
+	opts    PollerOptions
+
+	backoff RetryPolicy
+	run     runState
+
+	mu       sync.Mutex
+	inFlight map[int64]struct{}
+	attempts map[int64]int // failed handling attempts of notifications still in the queue
+	finished chan struct{} // signalled whenever a notification has been processed
+}
+
+// NewPoller creates a Poller dispatching notifications to handler
+func (c *Client) NewPoller(handler *WebhookHandler, opts PollerOptions) *Poller {
+	opts = opts.withDefaults()
+	return &Poller{
+		client:   c,
+		handler:  handler,
+		opts:     opts,
+		backoff:  RetryPolicy{BaseDelay: opts.MinBackoff, MaxDelay: opts.MaxBackoff, Jitter: 0.2},
+		inFlight: make(map[int64]struct{}),
+		attempts: make(map[int64]int),
+		finished: make(chan struct{}, 1),
+	}
+}
+
+// StartReceivingNotifications starts receiving notifications via polling with default
+// PollerOptions. It blocks until ctx is cancelled.
+func (c *Client) StartReceivingNotifications(ctx context.Context, handler *WebhookHandler) error {
+	return c.NewPoller(handler, PollerOptions{}).Run(ctx)
+}
+
+// Run polls the queue until ctx is cancelled and returns the context error once the
+// notifications being handled are done
+func (p *Poller) Run(ctx context.Context) error {
+	slots := make(chan struct{}, p.opts.MaxInFlight)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	failures := 0
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		data, err := p.client.receiveRawNotification(ctx, p.opts.ReceiveTimeout)
+		if err != nil {
+			<-slots
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			failures++
+			p.reportError(fmt.Errorf("failed to receive notification: %w", err))
+			if err := sleepContext(ctx, p.backoff.delay(failures, nil)); err != nil {
+				return err
+			}
+			continue
+		}
+		failures = 0
+
+		if data == nil {
+			<-slots
+			// With long polling the server has already waited for a notification
+			if p.opts.ReceiveTimeout > 0 {
+				continue
+			}
+			if err := sleepContext(ctx, p.opts.IdleInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		notification, err := DecodeNotification(data)
+		if err != nil {
+			<-slots
+			p.discard(ctx, data, err)
+			continue
+		}
+
+		// The queue returns a notification again until it is deleted, so one that is
+		// still being handled means nothing else is available yet
+		if !p.claim(notification.ReceiptID) {
+			<-slots
+			if err := p.waitFinished(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Deleting the notification before handling it makes the queue return the next
+		// one, which is the only way to handle several at once
+		if p.opts.MaxInFlight > 1 {
+			if _, err := p.client.DeleteNotification(ctx, notification.ReceiptID); err != nil {
+				p.release(notification.ReceiptID)
+				<-slots
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				failures++
+				p.reportError(fmt.Errorf("failed to delete notification %d: %w", notification.ReceiptID, err))
+				if err := sleepContext(ctx, p.backoff.delay(failures, nil)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if ev := notification.Body; ev.Timestamp > 0 {
+			p.client.metrics.ObservePollLag(time.Since(ev.Time()))
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				p.release(notification.ReceiptID)
+				<-slots
+			}()
+
+			p.process(ctx, notification)
+		}()
+	}
+}
+
+// process handles a notification and deletes it from the queue according to the ack policy
+func (p *Poller) process(ctx context.Context, notification *Notification) {
+	ev := notification.Body
+
+	// A handler cut short by TimeoutMiddleware keeps running and may still acknowledge
+	// from its own goroutine
+	var acked atomic.Bool
+	if p.opts.AckPolicy == AckManual && p.opts.MaxInFlight == 1 {
+		var once sync.Once
+		var ackErr error
+		ev.ack = func(ctx context.Context) error {
+			once.Do(func() {
+				_, ackErr = p.client.DeleteNotification(context.WithoutCancel(ctx), notification.ReceiptID)
+				if ackErr == nil {
+					acked.Store(true)
+					// The notification may already be waiting for another attempt
+					p.forget(notification.ReceiptID)
+				}
+			})
+			return ackErr
+		}
+	}
+
+	var err error
+	if p.handler != nil {
+		if err = p.handler.HandleEvent(withEventSource(ctx, eventSourcePolling), ev); err != nil {
+			p.reportError(fmt.Errorf("failed to handle notification %d: %w", notification.ReceiptID, err))
+		}
+	}
+
+	if p.opts.MaxInFlight > 1 {
+		// Already deleted when received
+		if err != nil {
+			p.giveUp(ctx, notification, 1, err)
+		}
+		return
+	}
+
+	switch p.opts.AckPolicy {
+	case AckAlways:
+		p.ack(ctx, notification)
+	case AckOnSuccess:
+		if err == nil {
+			p.ack(ctx, notification)
+			return
+		}
+		p.retryLater(ctx, notification)
+	case AckManual:
+		if !acked.Load() {
+			p.retryLater(ctx, notification)
+		}
+		// Checked again as a late ack may have landed before the attempt was recorded
+		if acked.Load() {
+			p.forget(notification.ReceiptID)
+		}
+	default:
+		if err == nil {
+			p.ack(ctx, notification)
+			return
+		}
+		if p.retryLater(ctx, notification) < p.opts.MaxAttempts {
+			return
+		}
+		p.giveUp(ctx, notification, p.opts.MaxAttempts, err)
+		p.ack(ctx, notification)
+	}
+}
+
+// giveUp passes a notification that will not be handled again to DeadLetter or logs it
+func (p *Poller) giveUp(ctx context.Context, notification *Notification, attempts int, err error) {
+	if p.opts.DeadLetter != nil {
+		p.opts.DeadLetter(ctx, notification, err)
+		return
+	}
+	p.client.logger.ErrorContext(ctx, "giving up on notification",
+		"receiptId", notification.ReceiptID, "attempts", attempts, "error", err)
+}
+
+// ack deletes a notification from the queue. A handled notification is deleted even
+// during shutdown so it is not delivered again.
+func (p *Poller) ack(ctx context.Context, notification *Notification) {
+	p.forget(notification.ReceiptID)
+	if _, err := p.client.DeleteNotification(context.WithoutCancel(ctx), notification.ReceiptID); err != nil {
+		p.reportError(fmt.Errorf("failed to delete notification %d: %w", notification.ReceiptID, err))
+	}
+}
+
+// retryLater records a failed attempt and, unless it was the last one allowed, waits
+// before the notification is received again. It returns the number of failed attempts.
+func (p *Poller) retryLater(ctx context.Context, notification *Notification) int {
+	p.mu.Lock()
+	p.attempts[notification.ReceiptID]++
+	attempts := p.attempts[notification.ReceiptID]
+	p.mu.Unlock()
+
+	if p.opts.AckPolicy == AckAfterAttempts && attempts >= p.opts.MaxAttempts {
+		return attempts
+	}
+	_ = sleepContext(ctx, p.backoff.delay(attempts, nil))
+	return attempts
+}
+
+// forget drops the attempt count of a deleted notification
+func (p *Poller) forget(receiptID int64) {
+	p.mu.Lock()
+	delete(p.attempts, receiptID)
+	p.mu.Unlock()
+}
+
+// reportError passes an error to OnError or logs it
+func (p *Poller) reportError(err error) {
+	if p.opts.OnError != nil {
+		p.opts.OnError(err)
+		return
+	}
+	p.client.logger.Warn("notification polling error", "error", err)
+}
+
+// Ack deletes the notification of an event from the queue when it was received by a
+// Poller using AckManual. It must be called before the handler returns, otherwise the
+// notification is delivered again; a later call, e.g. from a handler that outlived its
+// TimeoutMiddleware, still deletes it, but possibly after it was handled again. Ack does nothing for other events, including those
+// of a Poller with MaxInFlight above one, which deletes notifications when received.
+func (e *WebhookEvent) Ack(ctx context.Context) error {
+	if e.ack == nil {
+		return nil
+	}
+	return e.ack(ctx)
+}
+
+// discard deletes a notification that cannot be decoded so that it does not block the queue
+func (p *Poller) discard(ctx context.Context, data []byte, decodeErr error) {
+	var envelope struct {
+		ReceiptID int64 `json:"receiptId"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.ReceiptID == 0 {
+		p.reportError(fmt.Errorf("failed to decode notification: %w", decodeErr))
+		return
+	}
+
+	p.reportError(fmt.Errorf("discarding notification %d: failed to decode notification: %w", envelope.ReceiptID, decodeErr))
+	if _, err := p.client.DeleteNotification(ctx, envelope.ReceiptID); err != nil {
+		p.reportError(fmt.Errorf("failed to delete notification %d: %w", envelope.ReceiptID, err))
+	}
+}
+
+// claim marks a receipt as in flight and reports whether it was not already
+func (p *Poller) claim(receiptID int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.inFlight[receiptID]; ok {
+		return false
+	}
+	p.inFlight[receiptID] = struct{}{}
+	return true
+}
+
+// release removes a receipt from the in-flight set
+func (p *Poller) release(receiptID int64) {
+	p.mu.Lock()
+	delete(p.inFlight, receiptID)
+	p.mu.Unlock()
+
+	select {
+	case p.finished <- struct{}{}:
+	default:
+	}
+}
+
+// waitFinished waits until a notification in flight has been processed, at most IdleInterval
+func (p *Poller) waitFinished(ctx context.Context) error {
+	timer := time.NewTimer(p.opts.IdleInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.finished:
+	case <-timer.C:
+	}
+	return nil
+}
+
+// receiveRawNotification retrieves the next queued notification, waiting up to
+// receiveTimeout on the server when it is positive. It returns nil when the queue is empty.
+func (c *Client) receiveRawNotification(ctx context.Context, receiveTimeout time.Duration) (json.RawMessage, error) {
+	path := c.basePath + "/receiveNotification"
+	if seconds := int(receiveTimeout / time.Second); seconds > 0 {
+		path = fmt.Sprintf("%s?receiveTimeout=%d", path, seconds)
+	}
+
+	var result json.RawMessage
+	if err := c.request(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(result)) == 0 || bytes.Equal(bytes.TrimSpace(result), []byte("null")) {
+		return nil, nil
+	}
+	return result, nil
+}
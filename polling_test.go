@@ -0,0 +1,342 @@
+package sdkwa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueServer emulates the notifications queue: receiveNotification returns the oldest
+// notification until deleteNotification removes it
+type queueServer struct {
+	mu       sync.Mutex
+	queue    []int64
+	failures int // number of receive requests to fail before serving
+	empty    int // number of receive requests to answer as if the queue was empty, after failures
+	queries  []string
+}
+
+func (q *queueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/receiveNotification"):
+		q.queries = append(q.queries, r.URL.RawQuery)
+		if q.failures > 0 {
+			q.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if q.empty > 0 {
+			q.empty--
+			fmt.Fprint(w, "null")
+			return
+		}
+		if len(q.queue) == 0 {
+			fmt.Fprint(w, "null")
+			return
+		}
+		fmt.Fprintf(w, `{"receiptId":%d,"body":{"typeWebhook":"incomingMessageReceived","idMessage":"M%d","senderData":{"chatId":"1@c.us"},"messageData":{"typeMessage":"textMessage","textMessageData":{"textMessage":"hi"}}}}`, q.queue[0], q.queue[0])
+	case strings.Contains(r.URL.Path, "/deleteNotification/"):
+		var id int64
+		fmt.Sscanf(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], "%d", &id)
+		for i, queued := range q.queue {
+			if queued == id {
+				q.queue = append(q.queue[:i], q.queue[i+1:]...)
+				break
+			}
+		}
+		fmt.Fprint(w, `{"result":true}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (q *queueServer) remaining() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queue)
+}
+
+// TestPoller_DrainsQueue tests that a backlog is handled without waiting between notifications,
+// nor after long polling requests that found the queue empty
+func TestPoller_DrainsQueue(t *testing.T) {
+	queue := &queueServer{failures: 2, empty: 2}
+	for i := int64(1); i <= 50; i++ {
+		queue.queue = append(queue.queue, i)
+	}
+	server := httptest.NewServer(queue)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	handler := NewWebhookHandler()
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		handled = append(handled, ev.IDMessage)
+		if len(handled) == 50 {
+			cancel()
+		}
+		return nil
+	})
+
+	poller := client.NewPoller(handler, PollerOptions{
+		ReceiveTimeout: 20 * time.Second,
+		MinBackoff:     time.Millisecond,
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue was not drained")
+	}
+
+	require.Len(t, handled, 50)
+	assert.Equal(t, "M1", handled[0])
+	assert.Equal(t, "M50", handled[49])
+	assert.Equal(t, 0, queue.remaining())
+	assert.Equal(t, "receiveTimeout=20", queue.queries[0])
+}
+
+// TestPoller_MaxInFlight tests that notifications are handled concurrently up to the limit,
+// each being deleted when received, and that failed ones go to DeadLetter
+func TestPoller_MaxInFlight(t *testing.T) {
+	queue := &queueServer{}
+	for i := int64(1); i <= 6; i++ {
+		queue.queue = append(queue.queue, i)
+	}
+	server := httptest.NewServer(queue)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	var running, peak, handled int
+	handler := NewWebhookHandler()
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		if peak == 3 {
+			releaseOnce.Do(func() { close(release) })
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		defer mu.Unlock()
+		running--
+		if handled++; handled == 6 {
+			cancel()
+		}
+		if ev.IDMessage == "M5" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	var dead []int64
+	poller := client.NewPoller(handler, PollerOptions{
+		MaxInFlight: 3,
+		AckPolicy:   AckOnSuccess,
+		DeadLetter: func(ctx context.Context, notification *Notification, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			dead = append(dead, notification.ReceiptID)
+		},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notifications were not handled")
+	}
+
+	assert.Equal(t, 3, peak)
+	assert.Equal(t, 6, handled)
+	assert.Equal(t, []int64{5}, dead)
+	assert.Equal(t, 0, queue.remaining())
+	assert.LessOrEqual(t, len(queue.queries), 7)
+}
+
+// TestPoller_AckPolicies tests when notifications are deleted under each ack policy
+func TestPoller_AckPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      AckPolicy
+		failures    int // handler failures before it succeeds
+		manualAckOn int // call on which the handler acknowledges with AckManual
+		wantCalls   int
+		wantDead    int
+	}{
+		{"on success", AckOnSuccess, 2, 0, 3, 0},
+		{"after attempts", AckAfterAttempts, 100, 0, 2, 1},
+		{"always", AckAlways, 100, 0, 1, 0},
+		{"manual", AckManual, 0, 2, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &queueServer{queue: []int64{7}}
+			server := httptest.NewServer(queue)
+			defer server.Close()
+			client := newTestClient(t, server, Options{})
+
+			var mu sync.Mutex
+			calls := 0
+			handler := NewWebhookHandler()
+			handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+				mu.Lock()
+				calls++
+				call := calls
+				mu.Unlock()
+
+				if call == tt.manualAckOn {
+					require.NoError(t, ev.Ack(ctx))
+				}
+				if call <= tt.failures {
+					return fmt.Errorf("attempt %d failed", call)
+				}
+				return nil
+			})
+
+			var dead []*Notification
+			poller := client.NewPoller(handler, PollerOptions{
+				IdleInterval: time.Millisecond,
+				MinBackoff:   time.Millisecond,
+				AckPolicy:    tt.policy,
+				MaxAttempts:  2,
+				DeadLetter: func(ctx context.Context, notification *Notification, err error) {
+					dead = append(dead, notification)
+				},
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- poller.Run(ctx) }()
+
+			require.Eventually(t, func() bool { return queue.remaining() == 0 }, 5*time.Second, time.Millisecond)
+			cancel()
+			<-done
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Len(t, dead, tt.wantDead)
+		})
+	}
+}
+
+// TestPoller_ManualAckAfterTimeout tests acknowledging from a handler that outlived its timeout
+func TestPoller_ManualAckAfterTimeout(t *testing.T) {
+	queue := &queueServer{queue: []int64{7}}
+	server := httptest.NewServer(queue)
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	handler := NewWebhookHandler()
+	handler.Use(TimeoutMiddleware(5 * time.Millisecond))
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		<-ctx.Done()
+		return ev.Ack(ctx)
+	})
+
+	poller := client.NewPoller(handler, PollerOptions{
+		IdleInterval: time.Millisecond,
+		MinBackoff:   time.Millisecond,
+		AckPolicy:    AckManual,
+		OnError:      func(err error) {},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return queue.remaining() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// The late ack forgets the attempts recorded after the timeout
+	assert.Eventually(t, func() bool {
+		poller.mu.Lock()
+		defer poller.mu.Unlock()
+		return len(poller.attempts) == 0
+	}, time.Second, time.Millisecond)
+}
+
+// TestPoller_RawCallbackPayload tests that raw callbacks receive polled payloads unchanged
+func TestPoller_RawCallbackPayload(t *testing.T) {
+	body := `{"typeWebhook":"incomingMessageReceived","idMessage":"IMG","timestamp":1588091580,` +
+		`"senderData":{"chatId":"1@c.us","sender":"1@c.us","senderPhoneNumber":79001234567},` +
+		`"messageData":{"typeMessage":"imageMessage","isForwarded":false,` +
+		`"fileMessageData":{"downloadUrl":"https://example.com/a.jpg","caption":"","newField":{"a":1}}}}`
+
+	var mu sync.Mutex
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/receiveNotification") && !served:
+			fmt.Fprintf(w, `{"receiptId":1,"body":%s}`, body)
+		case strings.HasSuffix(r.URL.Path, "/receiveNotification"):
+			fmt.Fprint(w, "null")
+		default:
+			served = true
+			fmt.Fprint(w, `{"result":true}`)
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got map[string]interface{}
+	handler := NewWebhookHandler()
+	handler.OnIncomingMessageFile(func(data map[string]interface{}) error {
+		got = data
+		cancel()
+		return nil
+	})
+
+	poller := client.NewPoller(handler, PollerOptions{IdleInterval: time.Millisecond})
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx) }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not handled")
+	}
+
+	encoded, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, body, string(encoded))
+}
@@ -0,0 +1,152 @@
+// Package prommetrics implements sdkwa.Metrics with Prometheus collectors
+package prommetrics
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	sdkwa "github.com/sdkwa/whatsapp-api-client-go"
+)
+
+// Options contains configuration options for the Prometheus metrics
+type Options struct {
+	Namespace  string                // Prefix of the metric names, defaults to sdkwa
+	Registerer prometheus.Registerer // Registry of the collectors, defaults to prometheus.DefaultRegisterer
+	Buckets    []float64             // Buckets of the duration histograms in seconds, defaults to prometheus.DefBuckets
+}
+
+// Metrics records the measurements of a client and its receivers and handlers as
+// Prometheus metrics:
+//
+//	sdkwa_requests_total{method,status}                API request attempts
+//	sdkwa_request_duration_seconds{method,status}      API request attempt latency
+//	sdkwa_request_retries_total{method}                API request retries
+//	sdkwa_rate_limit_wait_seconds                      Time spent waiting for the client rate limiter
+//	sdkwa_notifications_received_total{type,source}    Events received by webhook handlers
+//	sdkwa_notifications_handled_total{type}            Events handled successfully
+//	sdkwa_notifications_failed_total{type}             Events whose handler returned an error
+//	sdkwa_notification_duration_seconds{type}          Event handling time
+//	sdkwa_websocket_reconnects_total                   WebSocket reconnection attempts
+//	sdkwa_polling_lag_seconds                          Age of notifications taken from the queue
+//
+// The status label is "0" for requests that failed in transport.
+type Metrics struct {
+	requests            *prometheus.CounterVec
+	requestDuration     *prometheus.HistogramVec
+	retries             *prometheus.CounterVec
+	rateLimitWait       prometheus.Histogram
+	received            *prometheus.CounterVec
+	handled             *prometheus.CounterVec
+	failed              *prometheus.CounterVec
+	eventDuration       *prometheus.HistogramVec
+	websocketReconnects prometheus.Counter
+	pollLag             prometheus.Histogram
+}
+
+var _ sdkwa.Metrics = (*Metrics)(nil)
+
+// New creates the metrics and registers them
+func New(opts Options) (*Metrics, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = "sdkwa"
+	}
+	if opts.Registerer == nil {
+		opts.Registerer = prometheus.DefaultRegisterer
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	ns := opts.Namespace
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "requests_total", Help: "API request attempts by method and HTTP status.",
+		}, []string{"method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "request_duration_seconds", Help: "API request attempt latency by method and HTTP status.", Buckets: opts.Buckets,
+		}, []string{"method", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "request_retries_total", Help: "API request retries by method.",
+		}, []string{"method"}),
+		rateLimitWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns, Name: "rate_limit_wait_seconds", Help: "Time requests waited for the client rate limiter.", Buckets: opts.Buckets,
+		}),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "notifications_received_total", Help: "Events received by type and source.",
+		}, []string{"type", "source"}),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "notifications_handled_total", Help: "Events handled successfully by type.",
+		}, []string{"type"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "notifications_failed_total", Help: "Events whose handling failed by type.",
+		}, []string{"type"}),
+		eventDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "notification_duration_seconds", Help: "Event handling time by type.", Buckets: opts.Buckets,
+		}, []string{"type"}),
+		websocketReconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "websocket_reconnects_total", Help: "WebSocket reconnection attempts.",
+		}),
+		pollLag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns, Name: "polling_lag_seconds", Help: "Age of notifications taken from the queue.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 10),
+		}),
+	}
+
+	collectors := []prometheus.Collector{
+		m.requests, m.requestDuration, m.retries, m.rateLimitWait,
+		m.received, m.handled, m.failed, m.eventDuration,
+		m.websocketReconnects, m.pollLag,
+	}
+	for _, c := range collectors {
+		if err := opts.Registerer.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveRequest implements sdkwa.Metrics
+func (m *Metrics) ObserveRequest(method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, code).Inc()
+	m.requestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// ObserveRetry implements sdkwa.Metrics
+func (m *Metrics) ObserveRetry(method string) {
+	m.retries.WithLabelValues(method).Inc()
+}
+
+// ObserveRateLimitWait implements sdkwa.Metrics
+func (m *Metrics) ObserveRateLimitWait(wait time.Duration) {
+	m.rateLimitWait.Observe(wait.Seconds())
+}
+
+// ObserveEventReceived implements sdkwa.Metrics
+func (m *Metrics) ObserveEventReceived(webhookType sdkwa.WebhookType, source string) {
+	m.received.WithLabelValues(string(webhookType), source).Inc()
+}
+
+// ObserveEvent implements sdkwa.EventObserver
+func (m *Metrics) ObserveEvent(webhookType sdkwa.WebhookType, duration time.Duration, err error) {
+	if err != nil {
+		m.failed.WithLabelValues(string(webhookType)).Inc()
+	} else {
+		m.handled.WithLabelValues(string(webhookType)).Inc()
+	}
+	m.eventDuration.WithLabelValues(string(webhookType)).Observe(duration.Seconds())
+}
+
+// ObserveWebSocketReconnect implements sdkwa.Metrics
+func (m *Metrics) ObserveWebSocketReconnect() {
+	m.websocketReconnects.Inc()
+}
+
+// ObservePollLag implements sdkwa.Metrics
+func (m *Metrics) ObservePollLag(lag time.Duration) {
+	m.pollLag.Observe(lag.Seconds())
+}
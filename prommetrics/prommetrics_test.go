@@ -0,0 +1,83 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdkwa "github.com/sdkwa/whatsapp-api-client-go"
+)
+
+// TestMetrics_Client tests request, retry and rate limiter metrics of a client
+func TestMetrics_Client(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"idMessage":"MSG1"}`)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	metrics, err := New(Options{Registerer: registry})
+	require.NoError(t, err)
+
+	client, err := sdkwa.NewClient(sdkwa.Options{
+		APIHost:          server.URL,
+		IDInstance:       "test-instance",
+		APITokenInstance: "test-token",
+		Retry:            sdkwa.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		RateLimit:        sdkwa.RateLimitOptions{RequestsPerSecond: 100},
+		Metrics:          metrics,
+	})
+	require.NoError(t, err)
+
+	_, err = client.SendMessage(context.Background(), sdkwa.SendMessageParams{ChatID: "1@c.us", Message: "hi"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("sendMessage", "503")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("sendMessage", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.retries.WithLabelValues("sendMessage")))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.requestDuration))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.rateLimitWait))
+
+	_, err = New(Options{Registerer: registry})
+	assert.Error(t, err)
+}
+
+// TestMetrics_Webhook tests event metrics of a webhook handler
+func TestMetrics_Webhook(t *testing.T) {
+	metrics, err := New(Options{Registerer: prometheus.NewRegistry()})
+	require.NoError(t, err)
+
+	handler, err := sdkwa.NewWebhookHandlerWithOptions(sdkwa.WebhookOptions{Metrics: metrics})
+	require.NoError(t, err)
+	handler.OnAny(func(ctx context.Context, ev *sdkwa.WebhookEvent) error {
+		if ev.IDMessage == "bad" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	for _, id := range []string{"1", "2", "bad"} {
+		ev := &sdkwa.WebhookEvent{TypeWebhook: string(sdkwa.WebhookTypeIncomingMessageReceived), IDMessage: id}
+		_ = handler.HandleEvent(context.Background(), ev)
+	}
+
+	typ := string(sdkwa.WebhookTypeIncomingMessageReceived)
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.received.WithLabelValues(typ, "")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.handled.WithLabelValues(typ)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.failed.WithLabelValues(typ)))
+}
@@ -0,0 +1,214 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is returned in fail-fast mode when the client rate limiter has no capacity left
+var ErrThrottled = errors.New("sdkwa: request throttled by client rate limiter")
+
+// RateLimitMode controls what happens when the client rate limiter has no capacity left
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks until capacity is available or the context is done
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast returns ErrThrottled immediately
+	RateLimitFailFast
+)
+
+// RateLimitOptions configures the client-side token bucket rate limiter.
+// The zero value disables rate limiting.
+type RateLimitOptions struct {
+	RequestsPerSecond        float64       // Budget for all outgoing HTTP requests, zero disables the global limit
+	Burst                    int           // Maximum burst for the global budget, defaults to the rounded-up rate
+	PerChatRequestsPerSecond float64       // Budget for send methods per chat ID, zero disables the per-chat limit
+	PerChatBurst             int           // Maximum burst per chat, defaults to the rounded-up per-chat rate
+	Mode                     RateLimitMode // Blocking or fail-fast behavior, defaults to RateLimitWait
+}
+
+// rateLimiter combines a global bucket with lazily created per-chat buckets
+type rateLimiter struct {
+	mode   RateLimitMode
+	global *tokenBucket
+
+	chatRate  float64
+	chatBurst int
+	mu        sync.Mutex
+	chats     map[string]*tokenBucket
+}
+
+// maxIdleChatBuckets is the number of per-chat buckets kept before full (idle) buckets are evicted
+const maxIdleChatBuckets = 1024
+
+// newRateLimiter returns a limiter for the given options, or nil if rate limiting is disabled
+func newRateLimiter(opts RateLimitOptions) *rateLimiter {
+	if opts.RequestsPerSecond <= 0 && opts.PerChatRequestsPerSecond <= 0 {
+		return nil
+	}
+
+	l := &rateLimiter{
+		mode:      opts.Mode,
+		chatRate:  opts.PerChatRequestsPerSecond,
+		chatBurst: defaultBurst(opts.PerChatBurst, opts.PerChatRequestsPerSecond),
+		chats:     make(map[string]*tokenBucket),
+	}
+	if opts.RequestsPerSecond > 0 {
+		l.global = newTokenBucket(opts.RequestsPerSecond, defaultBurst(opts.Burst, opts.RequestsPerSecond))
+	}
+
+	return l
+}
+
+// defaultBurst returns burst, or the rate rounded up when burst is unset
+func defaultBurst(burst int, rate float64) int {
+	if burst > 0 {
+		return burst
+	}
+	return int(math.Max(1, math.Ceil(rate)))
+}
+
+// waitGlobal takes a token from the global bucket and returns how long the caller waited
+func (l *rateLimiter) waitGlobal(ctx context.Context) (time.Duration, error) {
+	if l == nil || l.global == nil {
+		return 0, nil
+	}
+	return l.take(ctx, l.global)
+}
+
+// waitChat takes a token from the bucket of the given chat and returns how long the caller waited
+func (l *rateLimiter) waitChat(ctx context.Context, chatID string) (time.Duration, error) {
+	if l == nil || l.chatRate <= 0 || chatID == "" {
+		return 0, nil
+	}
+	return l.take(ctx, l.chatBucket(chatID))
+}
+
+// chatBucket returns the bucket for a chat, creating it if needed
+func (l *rateLimiter) chatBucket(chatID string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.chats[chatID]; ok {
+		return bucket
+	}
+
+	if len(l.chats) >= maxIdleChatBuckets {
+		now := time.Now()
+		for id, bucket := range l.chats {
+			if bucket.full(now) {
+				delete(l.chats, id)
+			}
+		}
+	}
+
+	bucket := newTokenBucket(l.chatRate, l.chatBurst)
+	l.chats[chatID] = bucket
+	return bucket
+}
+
+// take acquires a token according to the limiter mode
+func (l *rateLimiter) take(ctx context.Context, bucket *tokenBucket) (time.Duration, error) {
+	if l.mode == RateLimitFailFast {
+		if wait, ok := bucket.tryTake(time.Now()); !ok {
+			return 0, fmt.Errorf("%w: capacity available in %s", ErrThrottled, wait)
+		}
+		return 0, nil
+	}
+
+	wait := bucket.reserve(time.Now())
+	if err := sleepContext(ctx, wait); err != nil {
+		bucket.refund()
+		return 0, err
+	}
+	return wait, nil
+}
+
+// tokenBucket is a classic token bucket refilled continuously at a fixed rate
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// advance refills the bucket up to now; the caller must hold the lock
+func (b *tokenBucket) advance(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// waitFor returns how long it takes until the bucket holds a full token; the caller must hold the lock
+func (b *tokenBucket) waitFor() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// reserve takes a token, possibly going into debt, and returns how long to wait before using it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	wait := b.waitFor()
+	b.tokens--
+	return wait
+}
+
+// tryTake takes a token only if one is available, otherwise it returns the time until one is
+func (b *tokenBucket) tryTake(now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	if b.tokens < 1 {
+		return b.waitFor(), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// refund returns a previously reserved token that was never used
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// full reports whether the bucket has refilled completely, i.e. it has been idle
+func (b *tokenBucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	return b.tokens >= b.burst
+}
+
+// throttleChat applies the per-chat rate limit for send methods
+func (c *Client) throttleChat(ctx context.Context, chatID string) error {
+	wait, err := c.limiter.waitChat(ctx, chatID)
+	if c.limiter != nil {
+		c.metrics.ObserveRateLimitWait(wait)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOKServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"idMessage":"ok"}`))
+	}))
+}
+
+// TestRateLimit_FailFastGlobal tests that the global budget rejects requests beyond the burst
+func TestRateLimit_FailFastGlobal(t *testing.T) {
+	server := newOKServer()
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		RateLimit: RateLimitOptions{RequestsPerSecond: 1, Burst: 2, Mode: RateLimitFailFast},
+	})
+
+	ctx := context.Background()
+	_, err := client.GetStateInstance(ctx)
+	require.NoError(t, err)
+	_, err = client.GetStateInstance(ctx)
+	require.NoError(t, err)
+	_, err = client.GetStateInstance(ctx)
+	assert.ErrorIs(t, err, ErrThrottled)
+}
+
+// TestRateLimit_PerChat tests that each chat has its own budget for send methods
+func TestRateLimit_PerChat(t *testing.T) {
+	server := newOKServer()
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		RateLimit: RateLimitOptions{PerChatRequestsPerSecond: 0.1, Mode: RateLimitFailFast},
+	})
+
+	ctx := context.Background()
+	_, err := client.SendMessage(ctx, SendMessageParams{ChatID: "79999999999@c.us", Message: "1"})
+	require.NoError(t, err)
+	_, err = client.SendMessage(ctx, SendMessageParams{ChatID: "79999999999@c.us", Message: "2"})
+	assert.ErrorIs(t, err, ErrThrottled)
+
+	_, err = client.SendMessage(ctx, SendMessageParams{ChatID: "79999999998@c.us", Message: "1"})
+	assert.NoError(t, err)
+
+	// Non-send methods are only subject to the global budget
+	_, err = client.GetStateInstance(ctx)
+	assert.NoError(t, err)
+}
+
+// TestRateLimit_WaitMode tests that blocking mode delays requests and honors the context
+func TestRateLimit_WaitMode(t *testing.T) {
+	server := newOKServer()
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		RateLimit: RateLimitOptions{RequestsPerSecond: 20, Burst: 1},
+	})
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetStateInstance(ctx)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	slow := newTestClient(t, server, Options{
+		RateLimit: RateLimitOptions{RequestsPerSecond: 0.01, Burst: 1},
+	})
+	_, err := slow.GetStateInstance(ctx)
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = slow.GetStateInstance(timeoutCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestTokenBucket tests refill and refund arithmetic
+func TestTokenBucket(t *testing.T) {
+	bucket := newTokenBucket(10, 2)
+	now := bucket.last
+
+	_, ok := bucket.tryTake(now)
+	assert.True(t, ok)
+	_, ok = bucket.tryTake(now)
+	assert.True(t, ok)
+
+	wait, ok := bucket.tryTake(now)
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	_, ok = bucket.tryTake(now.Add(100 * time.Millisecond))
+	assert.True(t, ok)
+
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve(now.Add(100*time.Millisecond)))
+	bucket.refund()
+	assert.False(t, bucket.full(now.Add(100*time.Millisecond)))
+	assert.True(t, bucket.full(now.Add(300*time.Millisecond)))
+}
@@ -0,0 +1,370 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Receiver is a source of notifications feeding a WebhookHandler: a WebhookServer,
+// a WebSocketClient or a Poller. Start blocks until the receiver stops and returns
+// nil after Stop, the context error when ctx ends, or the error that ended it.
+// Stop ends a running Start, waiting at most until ctx is done.
+type Receiver interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// ConnectedReceiver is a Receiver that can report whether it is currently connected
+type ConnectedReceiver interface {
+	Receiver
+	Connected() bool
+}
+
+// errAlreadyRunning is returned by Start when the receiver is already running
+var errAlreadyRunning = errors.New("receiver is already running")
+
+// runState tracks a running Start call so that Stop can end it and wait for it
+type runState struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopping bool
+}
+
+// begin marks the start of a run and returns its context, cancelled by stop
+func (s *runState) begin(ctx context.Context) (context.Context, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done != nil {
+		return nil, errAlreadyRunning
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	s.stopping = false
+	return ctx, nil
+}
+
+// end marks the end of a run and reports whether it was ended by stop
+func (s *runState) end() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancel()
+	close(s.done)
+	s.done = nil
+	return s.stopping
+}
+
+// stop cancels the current run, if any, and waits until it ends or ctx is done
+func (s *runState) stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.done == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	s.cancel()
+	done := s.done
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WebhookServer is a Receiver serving a WebhookHandler over HTTP on every path
+type WebhookServer struct {
+	handler *WebhookHandler
+	server  *http.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+// NewWebhookServer creates a WebhookServer listening on addr
+func NewWebhookServer(addr string, handler *WebhookHandler) *WebhookServer {
+	return &WebhookServer{
+		handler: handler,
+		server:  &http.Server{Addr: addr, Handler: handler},
+	}
+}
+
+// Addr returns the address the server listens on, or nil before Start
+func (s *WebhookServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Start listens and serves webhooks. When ctx ends the server shuts down gracefully.
+// A server cannot be started again once stopped.
+func (s *WebhookServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		_ = s.Stop(context.WithoutCancel(ctx))
+		<-errCh
+		return ctx.Err()
+	}
+}
+
+// Stop shuts the server down and drains webhooks queued for asynchronous processing
+func (s *WebhookServer) Stop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	return s.handler.Shutdown(ctx)
+}
+
+// Start runs the client with Run
+func (ws *WebSocketClient) Start(ctx context.Context) error {
+	return ws.Run(ctx)
+}
+
+// Stop closes the client, making Run return
+func (ws *WebSocketClient) Stop(ctx context.Context) error {
+	return ws.Close()
+}
+
+// Connected reports whether the client is currently connected
+func (ws *WebSocketClient) Connected() bool {
+	return ws.State() == WebSocketConnected
+}
+
+// Start polls the queue with Run until Stop is called or ctx ends
+func (p *Poller) Start(ctx context.Context) error {
+	runCtx, err := p.run.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = p.Run(runCtx)
+	if p.run.end() {
+		return nil
+	}
+	return err
+}
+
+// Stop ends polling and waits for the notifications being handled
+func (p *Poller) Stop(ctx context.Context) error {
+	return p.run.stop(ctx)
+}
+
+// MultiReceiver runs several receivers at once
+type MultiReceiver struct {
+	receivers []Receiver
+	run       runState
+}
+
+// NewMultiReceiver creates a Receiver running all the given receivers
+func NewMultiReceiver(receivers ...Receiver) *MultiReceiver {
+	return &MultiReceiver{receivers: receivers}
+}
+
+// Start runs every receiver until Stop is called or ctx ends. If a receiver fails,
+// the others are stopped and its error is returned.
+func (m *MultiReceiver) Start(ctx context.Context) error {
+	runCtx, err := m.run.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for _, r := range m.receivers {
+		wg.Add(1)
+		go func(r Receiver) {
+			defer wg.Done()
+
+			err := r.Start(runCtx)
+			if err != nil && runCtx.Err() == nil {
+				once.Do(func() {
+					firstErr = err
+					go func() { _ = m.stopAll(context.WithoutCancel(runCtx)) }()
+				})
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	stopped := m.run.end()
+	switch {
+	case firstErr != nil:
+		return firstErr
+	case stopped:
+		return nil
+	default:
+		return ctx.Err()
+	}
+}
+
+// Stop stops every receiver and waits until Start returns
+func (m *MultiReceiver) Stop(ctx context.Context) error {
+	err := m.stopAll(ctx)
+	if stopErr := m.run.stop(ctx); stopErr != nil {
+		return errors.Join(err, stopErr)
+	}
+	return err
+}
+
+// stopAll stops the receivers concurrently
+func (m *MultiReceiver) stopAll(ctx context.Context) error {
+	errs := make([]error, len(m.receivers))
+	var wg sync.WaitGroup
+	for i, r := range m.receivers {
+		wg.Add(1)
+		go func(i int, r Receiver) {
+			defer wg.Done()
+			errs[i] = r.Stop(ctx)
+		}(i, r)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// FailoverOptions configures a FailoverReceiver
+type FailoverOptions struct {
+	CheckInterval time.Duration // Interval between checks of the primary connection, defaults to 1s
+	Grace         time.Duration // Time the primary may stay disconnected before the fallback starts, defaults to 5s
+
+	// OnSwitch is called when the fallback starts (true) or stops (false)
+	OnSwitch func(fallbackActive bool)
+
+	Logger *slog.Logger // Receives errors of the fallback receiver, defaults to slog.Default()
+}
+
+// FailoverReceiver runs a primary receiver, typically a WebSocketClient with
+// AutoReconnect, and a fallback receiver, typically a Poller, only while the primary
+// is disconnected. Both should feed the same WebhookHandler; a Deduplicator on the
+// handler skips events received by both.
+type FailoverReceiver struct {
+	primary  ConnectedReceiver
+	fallback Receiver
+	opts     FailoverOptions
+	run      runState
+}
+
+// NewFailoverReceiver creates a FailoverReceiver
+func NewFailoverReceiver(primary ConnectedReceiver, fallback Receiver, opts FailoverOptions) *FailoverReceiver {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = time.Second
+	}
+	if opts.Grace <= 0 {
+		opts.Grace = 5 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &FailoverReceiver{primary: primary, fallback: fallback, opts: opts}
+}
+
+// Start runs the primary receiver and switches the fallback on and off as the primary
+// disconnects and reconnects. It returns when the primary stops.
+func (f *FailoverReceiver) Start(ctx context.Context) error {
+	runCtx, err := f.run.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	primaryDone := make(chan error, 1)
+	go func() {
+		primaryDone <- f.primary.Start(runCtx)
+	}()
+
+	ticker := time.NewTicker(f.opts.CheckInterval)
+	defer ticker.Stop()
+
+	var fallbackDone chan error
+	var disconnectedSince time.Time
+	for {
+		select {
+		case err = <-primaryDone:
+			if fallbackDone != nil {
+				_ = f.fallback.Stop(context.WithoutCancel(runCtx))
+				<-fallbackDone
+				f.switched(false)
+			}
+			if f.run.end() {
+				return nil
+			}
+			return err
+
+		case fallbackErr := <-fallbackDone:
+			fallbackDone = nil
+			if fallbackErr != nil && runCtx.Err() == nil {
+				f.opts.Logger.Warn("fallback receiver stopped", "error", fallbackErr)
+			}
+			f.switched(false)
+
+		case <-ticker.C:
+			if f.primary.Connected() {
+				disconnectedSince = time.Time{}
+				if fallbackDone != nil {
+					if err := f.fallback.Stop(runCtx); err != nil {
+						f.opts.Logger.Warn("failed to stop fallback receiver", "error", err)
+					}
+				}
+				continue
+			}
+
+			if disconnectedSince.IsZero() {
+				disconnectedSince = time.Now()
+			}
+			if fallbackDone == nil && time.Since(disconnectedSince) >= f.opts.Grace {
+				fallbackDone = make(chan error, 1)
+				go func(done chan error) {
+					done <- f.fallback.Start(runCtx)
+				}(fallbackDone)
+				f.switched(true)
+			}
+		}
+	}
+}
+
+// Stop stops both receivers and waits until Start returns
+func (f *FailoverReceiver) Stop(ctx context.Context) error {
+	err := errors.Join(f.fallback.Stop(ctx), f.primary.Stop(ctx))
+	if stopErr := f.run.stop(ctx); stopErr != nil {
+		return errors.Join(err, stopErr)
+	}
+	return err
+}
+
+// switched notifies OnSwitch
+func (f *FailoverReceiver) switched(fallbackActive bool) {
+	if f.opts.OnSwitch != nil {
+		f.opts.OnSwitch(fallbackActive)
+	}
+}
@@ -0,0 +1,137 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReceiver runs until stopped and records how often it was started
+type fakeReceiver struct {
+	connected atomic.Bool
+	starts    atomic.Int32
+	running   atomic.Bool
+	run       runState
+}
+
+func (f *fakeReceiver) Start(ctx context.Context) error {
+	runCtx, err := f.run.begin(ctx)
+	if err != nil {
+		return err
+	}
+	f.starts.Add(1)
+	f.running.Store(true)
+	<-runCtx.Done()
+	f.running.Store(false)
+	if f.run.end() {
+		return nil
+	}
+	return ctx.Err()
+}
+
+func (f *fakeReceiver) Stop(ctx context.Context) error { return f.run.stop(ctx) }
+func (f *fakeReceiver) Connected() bool                { return f.connected.Load() }
+
+// TestPoller_StartStop tests that Stop ends Start and that the poller can be restarted
+func TestPoller_StartStop(t *testing.T) {
+	server := httptest.NewServer(&queueServer{})
+	defer server.Close()
+	poller := newTestClient(t, server, Options{}).NewPoller(nil, PollerOptions{IdleInterval: time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		done := make(chan error, 1)
+		go func() { done <- poller.Start(context.Background()) }()
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, poller.Stop(context.Background()))
+		assert.NoError(t, <-done)
+	}
+}
+
+// TestMultiReceiver tests that a webhook server and a poller feed the same handler
+func TestMultiReceiver(t *testing.T) {
+	queue := &queueServer{queue: []int64{1}}
+	api := httptest.NewServer(queue)
+	defer api.Close()
+	client := newTestClient(t, api, Options{})
+
+	var mu sync.Mutex
+	var received []WebhookType
+	handler := NewWebhookHandler()
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, WebhookType(ev.TypeWebhook))
+		return nil
+	})
+
+	server := NewWebhookServer("127.0.0.1:0", handler)
+	multi := NewMultiReceiver(server, client.NewPoller(handler, PollerOptions{IdleInterval: time.Millisecond}))
+
+	done := make(chan error, 1)
+	go func() { done <- multi.Start(context.Background()) }()
+
+	require.Eventually(t, func() bool { return server.Addr() != nil }, time.Second, time.Millisecond)
+	resp, err := http.Post("http://"+server.Addr().String()+"/webhook", "application/json", strings.NewReader(stateChangedJSON))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, 5*time.Second, time.Millisecond)
+
+	require.NoError(t, multi.Stop(context.Background()))
+	assert.NoError(t, <-done)
+	assert.ElementsMatch(t, []WebhookType{WebhookTypeStateInstanceChanged, WebhookTypeIncomingMessageReceived}, received)
+}
+
+// TestFailoverReceiver tests that the fallback runs only while the primary is disconnected
+func TestFailoverReceiver(t *testing.T) {
+	primary := &fakeReceiver{}
+	fallback := &fakeReceiver{}
+
+	var switches []bool
+	var mu sync.Mutex
+	failover := NewFailoverReceiver(primary, fallback, FailoverOptions{
+		CheckInterval: time.Millisecond,
+		Grace:         20 * time.Millisecond,
+		OnSwitch: func(active bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			switches = append(switches, active)
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- failover.Start(ctx) }()
+
+	primary.connected.Store(true)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, int32(0), fallback.starts.Load())
+
+	primary.connected.Store(false)
+	require.Eventually(t, fallback.running.Load, time.Second, time.Millisecond)
+
+	primary.connected.Store(true)
+	require.Eventually(t, func() bool { return !fallback.running.Load() }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []bool{true, false}, switches)
+	assert.Equal(t, int32(1), fallback.starts.Load())
+}
@@ -0,0 +1,163 @@
+package sdkwa
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of failed API requests.
+// The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts including the first one, values below 2 disable retries
+	BaseDelay            time.Duration // Delay before the first retry, doubled on every further attempt, defaults to 500 milliseconds
+	MaxDelay             time.Duration // Upper bound for a single delay, Retry-After included, defaults to 30 seconds
+	Jitter               float64       // Fraction of each delay that is randomized, between 0 and 1
+	RetryableStatusCodes []int         // HTTP status codes that are retried, defaults to 429, 500, 502, 503 and 504
+	RetryNetworkErrors   bool          // Retry requests that failed in transport (timeouts, connection resets, DNS failures)
+	RespectRetryAfter    bool          // Wait for the duration given in the Retry-After response header when present, up to MaxDelay
+
+	// ShouldRetry optionally replaces the built-in retry decision. statusCode is zero
+	// when the request failed before a response was received.
+	ShouldRetry func(statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most applications
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:        4,
+		BaseDelay:          500 * time.Millisecond,
+		MaxDelay:           30 * time.Second,
+		Jitter:             0.2,
+		RetryNetworkErrors: true,
+		RespectRetryAfter:  true,
+	}
+}
+
+// defaultRetryableStatusCodes are retried when RetryPolicy.RetryableStatusCodes is empty
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// withDefaults returns a copy of the policy with unset fields filled in
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 500 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if len(p.RetryableStatusCodes) == 0 {
+		p.RetryableStatusCodes = defaultRetryableStatusCodes
+	}
+	return p
+}
+
+// shouldRetry reports whether a request that failed on the given attempt should be sent again.
+// resp is nil when the request failed in transport.
+func (p RetryPolicy) shouldRetry(attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(statusCode, err)
+	}
+
+	if resp == nil {
+		return p.RetryNetworkErrors
+	}
+
+	// Never resend a request the server has already accepted
+	if statusCode < 400 {
+		return false
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the attempt following the given one
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// A server asking for a long wait must not stall the caller indefinitely
+			if retryAfter > p.MaxDelay {
+				return p.MaxDelay
+			}
+			return retryAfter
+		}
+	}
+
+	backoff := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if backoff > float64(p.MaxDelay) {
+		backoff = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		backoff -= backoff * p.Jitter * rand.Float64()
+	}
+
+	return time.Duration(backoff)
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
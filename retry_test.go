@@ -0,0 +1,165 @@
+package sdkwa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a client pointed at the given test server
+func newTestClient(t *testing.T, server *httptest.Server, opts Options) *Client {
+	t.Helper()
+
+	opts.APIHost = server.URL
+	if opts.IDInstance == "" {
+		opts.IDInstance = "test-instance"
+	}
+	if opts.APITokenInstance == "" {
+		opts.APITokenInstance = "test-token"
+	}
+
+	client, err := NewClient(opts)
+	require.NoError(t, err)
+	return client
+}
+
+// TestRetry_StatusCodes tests that retryable status codes are retried until success
+func TestRetry_StatusCodes(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"idMessage":"abc"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		Retry: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+
+	resp, err := client.SendMessage(context.Background(), SendMessageParams{ChatID: "79999999999@c.us", Message: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.IDMessage)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+// TestRetry_DisabledByDefault tests that the zero retry policy sends a single request
+func TestRetry_DisabledByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{})
+
+	_, err := client.GetStateInstance(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// TestRetry_NonRetryableStatus tests that client errors are not retried
+func TestRetry_NonRetryableStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"bad chatId"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		Retry: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond},
+	})
+
+	_, err := client.GetStateInstance(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// TestRetry_MultipartBodyResent tests that upload bodies are sent in full on every attempt
+func TestRetry_MultipartBodyResent(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "file contents", string(data))
+		assert.Equal(t, "79999999999@c.us", r.FormValue("chatId"))
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"idMessage":"upload"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	})
+
+	resp, err := client.SendFileByUpload(context.Background(), SendFileByUploadParams{
+		ChatID:   "79999999999@c.us",
+		File:     strings.NewReader("file contents"),
+		FileName: "test.txt",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "upload", resp.IDMessage)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestRetry_ContextCancelledDuringBackoff tests that waiting between attempts honors the context
+func TestRetry_ContextCancelledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Options{
+		Retry: RetryPolicy{MaxAttempts: 3, RespectRetryAfter: true},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetStateInstance(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestRetryPolicy_Delay tests backoff growth, capping and Retry-After parsing and clamping
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}.withDefaults()
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2, nil))
+	assert.Equal(t, 300*time.Millisecond, policy.delay(3, nil))
+
+	policy.RespectRetryAfter = true
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 300*time.Millisecond, policy.delay(1, resp))
+
+	policy.MaxDelay = 5 * time.Second
+	assert.Equal(t, 2*time.Second, policy.delay(1, resp))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	wait, ok := parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+}
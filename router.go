@@ -0,0 +1,100 @@
+package sdkwa
+
+// Predicate selects the events a handler registered with OnMatch receives
+type Predicate func(ev *WebhookEvent) bool
+
+// ForType matches events of any of the given webhook types. A type without a message
+// kind, such as WebhookTypeIncomingMessageReceived, matches all its message kinds.
+func ForType(types ...WebhookType) Predicate {
+	return func(ev *WebhookEvent) bool {
+		eventType := ev.Type()
+		baseType := WebhookType(ev.TypeWebhook)
+		for _, t := range types {
+			if t == eventType || t == baseType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ForChat matches events belonging to any of the given chats
+func ForChat(chatIDs ...string) Predicate {
+	return func(ev *WebhookEvent) bool {
+		chat := ev.Chat()
+		for _, chatID := range chatIDs {
+			if chat == chatID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FromSender matches message events sent by any of the given senders
+func FromSender(senders ...string) Predicate {
+	return func(ev *WebhookEvent) bool {
+		if ev.SenderData == nil {
+			return false
+		}
+		for _, sender := range senders {
+			if ev.SenderData.Sender == sender {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GroupChats matches events from group chats
+func GroupChats() Predicate {
+	return func(ev *WebhookEvent) bool {
+		return ChatID(ev.Chat()).IsGroup()
+	}
+}
+
+// PrivateChats matches events from personal (one-to-one) chats
+func PrivateChats() Predicate {
+	return func(ev *WebhookEvent) bool {
+		chat := ev.Chat()
+		return chat != "" && !ChatID(chat).IsGroup()
+	}
+}
+
+// ForMessenger matches events produced by an instance of the given messenger type
+func ForMessenger(messengerType MessengerType) Predicate {
+	return func(ev *WebhookEvent) bool {
+		return ev.InstanceData != nil && ev.InstanceData.TypeInstance == string(messengerType)
+	}
+}
+
+// AllOf matches events accepted by every predicate
+func AllOf(predicates ...Predicate) Predicate {
+	return func(ev *WebhookEvent) bool {
+		for _, p := range predicates {
+			if !p(ev) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf matches events accepted by at least one predicate
+func AnyOf(predicates ...Predicate) Predicate {
+	return func(ev *WebhookEvent) bool {
+		for _, p := range predicates {
+			if p(ev) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches events rejected by the predicate
+func Not(predicate Predicate) Predicate {
+	return func(ev *WebhookEvent) bool {
+		return !predicate(ev)
+	}
+}
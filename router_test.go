@@ -0,0 +1,121 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const groupTextJSON = `{
+	"typeWebhook": "incomingMessageReceived",
+	"instanceData": {"idInstance": 1, "wid": "79001234567@c.us", "typeInstance": "whatsapp"},
+	"senderData": {"chatId": "120363043968066561@g.us", "sender": "79001234569@c.us"},
+	"messageData": {"typeMessage": "textMessage", "textMessageData": {"textMessage": "group hello"}}
+}`
+
+// TestWebhookHandler_MultipleSubscribers tests that handlers for the same type all run in order
+func TestWebhookHandler_MultipleSubscribers(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var order []string
+	handler.OnIncomingMessageText(func(data map[string]interface{}) error {
+		order = append(order, "raw1")
+		return nil
+	})
+	handler.OnIncomingMessageText(func(data map[string]interface{}) error {
+		order = append(order, "raw2")
+		return nil
+	})
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		order = append(order, "typed")
+		return nil
+	})
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error {
+		order = append(order, "any")
+		return nil
+	})
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	assert.Equal(t, []string{"raw1", "raw2", "typed", "any"}, order)
+}
+
+// TestWebhookHandler_StopsOnError tests that dispatch stops at the first failing handler
+func TestWebhookHandler_StopsOnError(t *testing.T) {
+	handler := NewWebhookHandler()
+	failure := errors.New("boom")
+
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		return failure
+	})
+	called := false
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		called = true
+		return nil
+	})
+
+	err := handler.HandleWebhook(decodeMap(t, textNotificationJSON))
+	assert.ErrorIs(t, err, failure)
+	assert.False(t, called)
+}
+
+// TestWebhookHandler_Unhandled tests the fallback for events without a subscriber
+func TestWebhookHandler_Unhandled(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	var any, unhandled []string
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error {
+		any = append(any, ev.TypeWebhook)
+		return nil
+	})
+	handler.OnUnhandled(func(ctx context.Context, ev *WebhookEvent) error {
+		unhandled = append(unhandled, ev.TypeWebhook)
+		return nil
+	})
+	handler.OnStateChanged(func(ctx context.Context, ev *StateChangedEvent) error {
+		return nil
+	})
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"stateInstanceChanged","stateInstance":"authorized"}`)))
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, `{"typeWebhook":"brandNewEvent"}`)))
+
+	assert.Equal(t, []string{"stateInstanceChanged", "brandNewEvent"}, any)
+	assert.Equal(t, []string{"brandNewEvent"}, unhandled)
+}
+
+// TestWebhookHandler_Predicates tests predicate-based routes
+func TestWebhookHandler_Predicates(t *testing.T) {
+	handler := NewWebhookHandler()
+
+	counts := map[string]int{}
+	count := func(name string) Handler {
+		return func(ctx context.Context, ev *WebhookEvent) error {
+			counts[name]++
+			return nil
+		}
+	}
+
+	handler.OnMatch(GroupChats(), count("group"))
+	handler.OnMatch(PrivateChats(), count("private"))
+	handler.OnMatch(ForChat("79001234568@c.us"), count("chat"))
+	handler.OnMatch(FromSender("79001234569@c.us"), count("sender"))
+	handler.OnMatch(ForMessenger(MessengerWhatsApp), count("whatsapp"))
+	handler.OnMatch(ForMessenger(MessengerTelegram), count("telegram"))
+	handler.OnMatch(AllOf(ForType(WebhookTypeIncomingMessageReceived), Not(GroupChats())), count("incomingPrivate"))
+	handler.OnMatch(AnyOf(ForChat("none"), ForType(WebhookTypeIncomingMessageReceivedTextMessage)), count("anyOf"))
+
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, textNotificationJSON)))
+	require.NoError(t, handler.HandleWebhook(decodeMap(t, groupTextJSON)))
+
+	assert.Equal(t, map[string]int{
+		"group":           1,
+		"private":         1,
+		"chat":            1,
+		"sender":          1,
+		"whatsapp":        2,
+		"incomingPrivate": 1,
+		"anyOf":           2,
+	}, counts)
+}
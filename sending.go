@@ -9,6 +9,13 @@ import (
 
 // SendMessage sends a text message to either a personal or group chat
 func (c *Client) SendMessage(ctx context.Context, params SendMessageParams, opts ...*RequestOptions) (*SendMessageResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+	if err := c.throttleChat(ctx, params.ChatID); err != nil {
+		return nil, err
+	}
+
 	var result SendMessageResponse
 	err := c.request(ctx, "POST", c.basePath+"/sendMessage", params, &result, opts...)
 	return &result, err
@@ -16,6 +23,13 @@ func (c *Client) SendMessage(ctx context.Context, params SendMessageParams, opts
 
 // SendContact sends a contact card message to a chat
 func (c *Client) SendContact(ctx context.Context, params SendContactParams, opts ...*RequestOptions) (*SendContactResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+	if err := c.throttleChat(ctx, params.ChatID); err != nil {
+		return nil, err
+	}
+
 	var result SendContactResponse
 	err := c.request(ctx, "POST", c.basePath+"/sendContact", params, &result, opts...)
 	return &result, err
@@ -23,6 +37,13 @@ func (c *Client) SendContact(ctx context.Context, params SendContactParams, opts
 
 // SendFileByUpload sends a file by uploading it using form-data
 func (c *Client) SendFileByUpload(ctx context.Context, params SendFileByUploadParams, opts ...*RequestOptions) (*SendFileByUploadResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+	if err := c.throttleChat(ctx, params.ChatID); err != nil {
+		return nil, err
+	}
+
 	fields := map[string]string{
 		"chatId": params.ChatID,
 	}
@@ -45,6 +66,13 @@ func (c *Client) SendFileByUpload(ctx context.Context, params SendFileByUploadPa
 
 // SendFileByURL sends a file by providing its URL
 func (c *Client) SendFileByURL(ctx context.Context, params SendFileByURLParams, opts ...*RequestOptions) (*SendFileByURLResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+	if err := c.throttleChat(ctx, params.ChatID); err != nil {
+		return nil, err
+	}
+
 	var result SendFileByURLResponse
 	err := c.request(ctx, "POST", c.basePath+"/sendFileByUrl", params, &result, opts...)
 	return &result, err
@@ -52,6 +80,13 @@ func (c *Client) SendFileByURL(ctx context.Context, params SendFileByURLParams,
 
 // SendLocation sends a location message to a chat
 func (c *Client) SendLocation(ctx context.Context, params SendLocationParams, opts ...*RequestOptions) (*SendLocationResponse, error) {
+	if err := c.checkChatID("chatId", params.ChatID, anyChat, opts); err != nil {
+		return nil, err
+	}
+	if err := c.throttleChat(ctx, params.ChatID); err != nil {
+		return nil, err
+	}
+
 	var result SendLocationResponse
 	err := c.request(ctx, "POST", c.basePath+"/sendLocation", params, &result, opts...)
 	return &result, err
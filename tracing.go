@@ -0,0 +1,175 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans created by this package
+const tracerName = "github.com/sdkwa/whatsapp-api-client-go"
+
+// Span attribute keys
+const (
+	attrInstanceID    = attribute.Key("sdkwa.instance_id")
+	attrMessengerType = attribute.Key("sdkwa.messenger_type")
+	attrChatID        = attribute.Key("sdkwa.chat_id")
+	attrAPIMethod     = attribute.Key("sdkwa.api_method")
+	attrAttempts      = attribute.Key("sdkwa.attempts")
+	attrWebhookType   = attribute.Key("sdkwa.webhook_type")
+	attrIDMessage     = attribute.Key("sdkwa.id_message")
+	attrReceiptID     = attribute.Key("sdkwa.receipt_id")
+	attrEventSource   = attribute.Key("sdkwa.event_source")
+	attrHTTPMethod    = attribute.Key("http.request.method")
+	attrHTTPStatus    = attribute.Key("http.response.status_code")
+)
+
+// newTracer returns the package tracer of provider, or of the global provider when nil
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// startRequestSpan starts the client span of an API call, named after the API method
+func (c *Client) startRequestSpan(ctx context.Context, info *RequestInfo) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attrAPIMethod.String(info.APIMethod),
+		attrInstanceID.String(c.idInstance),
+		attrHTTPMethod.String(info.HTTPMethod),
+	}
+	if messengerType := c.messengerTypeOf(info.Path); messengerType != "" {
+		attrs = append(attrs, attrMessengerType.String(messengerType))
+	}
+	if chatID := chatIDOf(info.Params); chatID != "" {
+		attrs = append(attrs, attrChatID.String(chatID))
+	}
+
+	return c.tracer.Start(ctx, info.APIMethod,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// endRequestSpan records the outcome of an API call on its span and ends it
+func endRequestSpan(span trace.Span, resp *http.Response, attempts int, err error) {
+	span.SetAttributes(attrAttempts.Int(attempts))
+	if resp != nil {
+		span.SetAttributes(attrHTTPStatus.Int(resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// messengerTypeOf returns the messenger type of an instance path (/whatsapp/{id}/...),
+// which may differ from the client's through RequestOptions, or "" for other paths
+func (c *Client) messengerTypeOf(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 || segments[1] != c.idInstance {
+		return ""
+	}
+	return segments[0]
+}
+
+// chatIDOf returns the chat ID among API method parameters: the ChatID field of a
+// parameter struct or the chatId form field of an upload
+func chatIDOf(params interface{}) string {
+	if fields, ok := params.(map[string]string); ok {
+		return fields["chatId"]
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	field := v.FieldByName("ChatID")
+	if field.IsValid() && field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
+
+// eventSourceKey and eventLinkKey are context keys describing where an event came from
+type (
+	eventSourceKey struct{}
+	eventLinkKey   struct{}
+)
+
+// Receivers of events, recorded on their spans
+const (
+	eventSourceWebhook   = "webhook"
+	eventSourceWebSocket = "websocket"
+	eventSourcePolling   = "polling"
+)
+
+// withEventSource records the receiver of the events handled with ctx
+func withEventSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, eventSourceKey{}, source)
+}
+
+// withEventLink records the span during which an event was received, when it is
+// handled later outside of it
+func withEventLink(ctx context.Context, sc trace.SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, eventLinkKey{}, sc)
+}
+
+// startEventSpan starts the consumer span of an event. The callbacks receive its context,
+// so their own spans become its children.
+func (w *WebhookHandler) startEventSpan(ctx context.Context, ev *WebhookEvent) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attrWebhookType.String(string(ev.Type())),
+	}
+	if ev.IDMessage != "" {
+		attrs = append(attrs, attrIDMessage.String(ev.IDMessage))
+	}
+	if chatID := ev.Chat(); chatID != "" {
+		attrs = append(attrs, attrChatID.String(chatID))
+	}
+	if ev.InstanceData != nil {
+		attrs = append(attrs, attrInstanceID.Int64(ev.InstanceData.IDInstance))
+	}
+	if ev.receiptID != 0 {
+		attrs = append(attrs, attrReceiptID.Int64(ev.receiptID))
+	}
+	if source, ok := ctx.Value(eventSourceKey{}).(string); ok {
+		attrs = append(attrs, attrEventSource.String(source))
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	}
+	if link, ok := ctx.Value(eventLinkKey{}).(trace.SpanContext); ok {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: link}))
+	}
+
+	return w.tracer.Start(ctx, "process "+ev.TypeWebhook, opts...)
+}
+
+// endEventSpan records the outcome of handling an event on its span and ends it
+func endEventSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,156 @@
+package sdkwa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracerProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// TestClient_Tracing tests that every API call is traced in one span covering its attempts
+func TestClient_Tracing(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"idMessage":"MSG1"}`)
+	}))
+	defer server.Close()
+
+	provider, recorder := newTestTracerProvider()
+	client := newTestClient(t, server, Options{
+		Retry:          RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		TracerProvider: provider,
+	})
+
+	_, err := client.SendMessage(context.Background(), SendMessageParams{ChatID: "1@c.us", Message: "hi"},
+		&RequestOptions{MessengerType: MessengerTelegram})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "sendMessage", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := spanAttrs(span)
+	assert.Equal(t, "test-instance", attrs[attrInstanceID].AsString())
+	assert.Equal(t, "telegram", attrs[attrMessengerType].AsString())
+	assert.Equal(t, "1@c.us", attrs[attrChatID].AsString())
+	assert.Equal(t, "POST", attrs[attrHTTPMethod].AsString())
+	assert.Equal(t, int64(200), attrs[attrHTTPStatus].AsInt64())
+	assert.Equal(t, int64(2), attrs[attrAttempts].AsInt64())
+}
+
+// TestClient_TracingError tests that failed API calls are recorded as span errors
+func TestClient_TracingError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"message":"bad chat"}`)
+	}))
+	defer server.Close()
+
+	provider, recorder := newTestTracerProvider()
+	client := newTestClient(t, server, Options{TracerProvider: provider})
+
+	_, err := client.GetChatHistory(context.Background(), GetChatHistoryParams{ChatID: "2@c.us"})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "getChatHistory", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "2@c.us", spanAttrs(spans[0])[attrChatID].AsString())
+	assert.Equal(t, int64(400), spanAttrs(spans[0])[attrHTTPStatus].AsInt64())
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+// TestWebhookHandler_Tracing tests that handlers run inside a consumer span of the event
+func TestWebhookHandler_Tracing(t *testing.T) {
+	provider, recorder := newTestTracerProvider()
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{TracerProvider: provider})
+	require.NoError(t, err)
+
+	handler.OnTextMessage(func(ctx context.Context, ev *TextMessageEvent) error {
+		_, span := provider.Tracer("test").Start(ctx, "callback")
+		span.End()
+		return errors.New("boom")
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, postWebhook(handler, textWebhookJSON("1@c.us", "MSG1")))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	callback, process := spans[0], spans[1]
+	assert.Equal(t, "process incomingMessageReceived", process.Name())
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+	assert.Equal(t, codes.Error, process.Status().Code)
+	assert.Equal(t, process.SpanContext().SpanID(), callback.Parent().SpanID())
+
+	attrs := spanAttrs(process)
+	assert.Equal(t, "incomingMessageReceived_textMessage", attrs[attrWebhookType].AsString())
+	assert.Equal(t, "MSG1", attrs[attrIDMessage].AsString())
+	assert.Equal(t, "1@c.us", attrs[attrChatID].AsString())
+	assert.Equal(t, "webhook", attrs[attrEventSource].AsString())
+}
+
+// TestWebhookHandler_TracingAsync tests that events handled asynchronously link to the request span
+func TestWebhookHandler_TracingAsync(t *testing.T) {
+	provider, recorder := newTestTracerProvider()
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{
+		Async:          AsyncOptions{Workers: 1},
+		TracerProvider: provider,
+	})
+	require.NoError(t, err)
+	handler.OnAny(func(ctx context.Context, ev *WebhookEvent) error { return nil })
+
+	ctx, requestSpan := provider.Tracer("test").Start(context.Background(), "POST /webhook")
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(textWebhookJSON("1@c.us", "MSG1"))).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	requestSpan.End()
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	var process sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "process incomingMessageReceived" {
+			process = span
+		}
+	}
+	require.NotNil(t, process)
+	assert.False(t, process.Parent().IsValid())
+	require.Len(t, process.Links(), 1)
+	assert.Equal(t, requestSpan.SpanContext().SpanID(), process.Links()[0].SpanContext.SpanID())
+	assert.Equal(t, "webhook", spanAttrs(process)[attrEventSource].AsString())
+}
@@ -0,0 +1,87 @@
+package sdkwa
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// newHTTPClient builds the HTTP client of a Client from its options. A caller-supplied
+// HTTPClient is used as is, and a caller-supplied Transport replaces the default one.
+func newHTTPClient(opts Options) (*http.Client, error) {
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient, nil
+	}
+
+	transport := opts.Transport
+	if transport == nil {
+		t, err := newTransport(opts)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+	}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// newTransport clones http.DefaultTransport, which honors the proxy environment
+// variables, and applies the proxy, TLS and connection pool options
+func newTransport(opts Options) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: scheme and host are required", opts.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+		RootCAs:            opts.RootCAs,
+		Certificates:       opts.Certificates,
+	}
+
+	if opts.MaxIdleConns > 0 {
+		transport.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+
+	return transport, nil
+}
+
+// connectionSettings returns the proxy and TLS configuration of the client's transport,
+// so that WebSocket connections go through the same proxy with the same certificates.
+// Both are nil when the transport is not an *http.Transport.
+func (c *Client) connectionSettings() (func(*http.Request) (*url.URL, error), *tls.Config) {
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		if c.httpClient.Transport != nil {
+			return nil, nil
+		}
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	var tlsConfig *tls.Config
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	return transport.Proxy, tlsConfig
+}
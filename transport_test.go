@@ -0,0 +1,89 @@
+package sdkwa
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	calls int32
+	next  http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return t.next.RoundTrip(req)
+}
+
+func stateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"stateInstance":"authorized"}`)
+	}))
+}
+
+// TestNewClient_CustomTransport tests that a caller-supplied client or transport is used
+func TestNewClient_CustomTransport(t *testing.T) {
+	server := stateServer()
+	defer server.Close()
+
+	transport := &countingTransport{next: http.DefaultTransport}
+	client := newTestClient(t, server, Options{Transport: transport})
+	_, err := client.GetStateInstance(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), transport.calls)
+
+	httpClient := &http.Client{Transport: transport}
+	client = newTestClient(t, server, Options{HTTPClient: httpClient})
+	_, err = client.GetStateInstance(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), transport.calls)
+}
+
+// TestNewClient_ProxyURL tests that requests go through the configured proxy
+func TestNewClient_ProxyURL(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		fmt.Fprint(w, `{"stateInstance":"authorized"}`)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(Options{
+		APIHost:          "http://api.example.invalid",
+		IDInstance:       "test-instance",
+		APITokenInstance: "test-token",
+		ProxyURL:         proxy.URL,
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetStateInstance(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "api.example.invalid", proxiedHost)
+
+	_, err = NewClient(Options{IDInstance: "i", APITokenInstance: "t", ProxyURL: "not a url"})
+	assert.Error(t, err)
+}
+
+// TestNewClient_RootCAs tests verification of the API server against a custom CA pool
+func TestNewClient_RootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"stateInstance":"authorized"}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server, Options{}).GetStateInstance(context.Background())
+	assert.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	_, err = newTestClient(t, server, Options{RootCAs: pool}).GetStateInstance(context.Background())
+	assert.NoError(t, err)
+}
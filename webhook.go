@@ -3,108 +3,300 @@ package sdkwa
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WebhookType represents the type of webhook event
 type WebhookType string
 
 const (
-	WebhookTypeStateInstanceChanged                       WebhookType = "stateInstanceChanged"
-	WebhookTypeOutgoingMessageStatus                      WebhookType = "outgoingMessageStatus"
-	WebhookTypeIncomingMessageReceivedTextMessage         WebhookType = "incomingMessageReceived_textMessage"
-	WebhookTypeIncomingMessageReceivedImageMessage        WebhookType = "incomingMessageReceived_imageMessage"
-	WebhookTypeIncomingMessageReceivedLocationMessage     WebhookType = "incomingMessageReceived_locationMessage"
-	WebhookTypeIncomingMessageReceivedContactMessage      WebhookType = "incomingMessageReceived_contactMessage"
-	WebhookTypeIncomingMessageReceivedExtendedTextMessage WebhookType = "incomingMessageReceived_extendedTextMessage"
-	WebhookTypeDeviceInfo                                 WebhookType = "deviceInfo"
+	WebhookTypeStateInstanceChanged                        WebhookType = "stateInstanceChanged"
+	WebhookTypeStatusInstanceChanged                       WebhookType = "statusInstanceChanged"
+	WebhookTypeOutgoingMessageStatus                       WebhookType = "outgoingMessageStatus"
+	WebhookTypeDeviceInfo                                  WebhookType = "deviceInfo"
+	WebhookTypeIncomingCall                                WebhookType = "incomingCall"
+	WebhookTypeIncomingBlock                               WebhookType = "incomingBlock"
+	WebhookTypeIncomingMessageReceived                     WebhookType = "incomingMessageReceived"
+	WebhookTypeOutgoingMessageReceived                     WebhookType = "outgoingMessageReceived"
+	WebhookTypeOutgoingAPIMessageReceived                  WebhookType = "outgoingAPIMessageReceived"
+	WebhookTypeIncomingMessageReceivedTextMessage          WebhookType = "incomingMessageReceived_textMessage"
+	WebhookTypeIncomingMessageReceivedExtendedTextMessage  WebhookType = "incomingMessageReceived_extendedTextMessage"
+	WebhookTypeIncomingMessageReceivedImageMessage         WebhookType = "incomingMessageReceived_imageMessage"
+	WebhookTypeIncomingMessageReceivedVideoMessage         WebhookType = "incomingMessageReceived_videoMessage"
+	WebhookTypeIncomingMessageReceivedAudioMessage         WebhookType = "incomingMessageReceived_audioMessage"
+	WebhookTypeIncomingMessageReceivedDocumentMessage      WebhookType = "incomingMessageReceived_documentMessage"
+	WebhookTypeIncomingMessageReceivedStickerMessage       WebhookType = "incomingMessageReceived_stickerMessage"
+	WebhookTypeIncomingMessageReceivedLocationMessage      WebhookType = "incomingMessageReceived_locationMessage"
+	WebhookTypeIncomingMessageReceivedContactMessage       WebhookType = "incomingMessageReceived_contactMessage"
+	WebhookTypeIncomingMessageReceivedContactsArrayMessage WebhookType = "incomingMessageReceived_contactsArrayMessage"
+	WebhookTypeIncomingMessageReceivedReactionMessage      WebhookType = "incomingMessageReceived_reactionMessage"
+	WebhookTypeIncomingMessageReceivedPollMessage          WebhookType = "incomingMessageReceived_pollMessage"
+	WebhookTypeIncomingMessageReceivedPollUpdateMessage    WebhookType = "incomingMessageReceived_pollUpdateMessage"
+	WebhookTypeIncomingMessageReceivedQuotedMessage        WebhookType = "incomingMessageReceived_quotedMessage"
 )
 
 // WebhookCallback represents a callback function for webhook events
 type WebhookCallback func(data map[string]interface{}) error
 
-// WebhookHandler handles webhook events from the SDKWA API
+// Handler processes a decoded webhook event
+type Handler func(ctx context.Context, ev *WebhookEvent) error
+
+// WebhookHandler handles webhook events from the SDKWA API.
+// Any number of handlers can subscribe to the same event; they run in registration order.
 type WebhookHandler struct {
-	callbacks map[WebhookType]WebhookCallback
+	mu          sync.RWMutex
+	routes      []route
+	unhandled   []Handler
+	middlewares []Middleware
+	verifier    *webhookVerifier
+	pool        *workerPool
+	dedup       Deduplicator
+	logger      *slog.Logger
+	tracer      trace.Tracer
+	metrics     Metrics
+}
+
+// WebhookOptions contains configuration options for a webhook handler
+type WebhookOptions struct {
+	AuthToken         string   // Expected webhook token (webhookUrlToken set via SetSettings), checked against the Authorization header
+	SignatureSecret   []byte   // Secret for HMAC-SHA256 verification of the request body, disabled when empty
+	SignatureHeader   string   // Header carrying the hex-encoded body signature, defaults to X-Signature
+	AllowedIPs        []string // Addresses or CIDR ranges allowed to deliver webhooks, all allowed when empty
+	TrustForwardedFor bool     // Take the client address from X-Forwarded-For, for use behind a reverse proxy
+	MaxBodySize       int64    // Maximum request body size in bytes, unlimited when zero
+
+	Async AsyncOptions // Asynchronous processing of webhooks received by ServeHTTP, disabled by default
+
+	// Deduplicator skips events that were already handled, e.g. a message received
+	// both by webhook and by polling. Deduplication is disabled when nil.
+	Deduplicator Deduplicator
+
+	// Logger receives the handler's logs, defaults to slog.Default(). The auth token and
+	// signature secret are always redacted.
+	Logger   *slog.Logger
+	LogLevel slog.Leveler // Minimum level of the handler's logs, left to the logger's handler when nil
+
+	// TracerProvider creates a consumer span for every handled event, defaults to the
+	// global provider of otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+
+	// Metrics receives the type and outcome of every received and handled event.
+	// Disabled when nil.
+	Metrics Metrics
+}
+
+// route is a subscription of a handler to the events matching a predicate
+type route struct {
+	match    Predicate
+	handler  Handler
+	catchAll bool // catch-all routes do not count as handling an event
 }
 
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler() *WebhookHandler {
-	return &WebhookHandler{
-		callbacks: make(map[WebhookType]WebhookCallback),
+	return &WebhookHandler{verifier: &webhookVerifier{}, logger: newLogger(nil, nil), tracer: newTracer(nil), metrics: nopMetrics{}}
+}
+
+// NewWebhookHandlerWithOptions creates a new webhook handler with the provided options
+func NewWebhookHandlerWithOptions(opts WebhookOptions) (*WebhookHandler, error) {
+	verifier, err := newWebhookVerifier(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WebhookHandler{
+		verifier: verifier,
+		dedup:    opts.Deduplicator,
+		logger:   newLogger(opts.Logger, opts.LogLevel, opts.AuthToken, string(opts.SignatureSecret)),
+		tracer:   newTracer(opts.TracerProvider),
+		metrics:  metricsOrNop(opts.Metrics),
 	}
+	if opts.Async.Workers > 0 {
+		w.pool = newWorkerPool(opts.Async, w.HandleEvent, w.logger)
+	}
+
+	return w, nil
+}
+
+// addRoute appends a route to the handler
+func (w *WebhookHandler) addRoute(r route) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.routes = append(w.routes, r)
+}
+
+// onRaw subscribes a raw callback to the given webhook types
+func (w *WebhookHandler) onRaw(callback WebhookCallback, types ...WebhookType) {
+	w.addRoute(route{
+		match: ForType(types...),
+		handler: func(ctx context.Context, ev *WebhookEvent) error {
+			return callback(ev.Raw())
+		},
+	})
 }
 
 // OnStateInstance registers a callback for state instance changed events
 func (w *WebhookHandler) OnStateInstance(callback WebhookCallback) {
-	w.callbacks[WebhookTypeStateInstanceChanged] = callback
+	w.onRaw(callback, WebhookTypeStateInstanceChanged)
 }
 
 // OnOutgoingMessageStatus registers a callback for outgoing message status events
 func (w *WebhookHandler) OnOutgoingMessageStatus(callback WebhookCallback) {
-	w.callbacks[WebhookTypeOutgoingMessageStatus] = callback
+	w.onRaw(callback, WebhookTypeOutgoingMessageStatus)
 }
 
 // OnIncomingMessageText registers a callback for incoming text message events
 func (w *WebhookHandler) OnIncomingMessageText(callback WebhookCallback) {
-	w.callbacks[WebhookTypeIncomingMessageReceivedTextMessage] = callback
+	w.onRaw(callback, WebhookTypeIncomingMessageReceivedTextMessage)
 }
 
-// OnIncomingMessageFile registers a callback for incoming file message events
+// OnIncomingMessageFile registers a callback for incoming file message events of every
+// media kind: images, videos, audio and voice notes, documents and stickers
 func (w *WebhookHandler) OnIncomingMessageFile(callback WebhookCallback) {
-	w.callbacks[WebhookTypeIncomingMessageReceivedImageMessage] = callback
+	types := make([]WebhookType, 0, len(mediaMessageTypes))
+	for _, messageType := range mediaMessageTypes {
+		types = append(types, incomingMessageType(messageType))
+	}
+	w.onRaw(callback, types...)
 }
 
 // OnIncomingMessageLocation registers a callback for incoming location message events
 func (w *WebhookHandler) OnIncomingMessageLocation(callback WebhookCallback) {
-	w.callbacks[WebhookTypeIncomingMessageReceivedLocationMessage] = callback
+	w.onRaw(callback, WebhookTypeIncomingMessageReceivedLocationMessage)
 }
 
 // OnIncomingMessageContact registers a callback for incoming contact message events
 func (w *WebhookHandler) OnIncomingMessageContact(callback WebhookCallback) {
-	w.callbacks[WebhookTypeIncomingMessageReceivedContactMessage] = callback
+	w.onRaw(callback, WebhookTypeIncomingMessageReceivedContactMessage)
 }
 
 // OnIncomingMessageExtendedText registers a callback for incoming extended text message events
 func (w *WebhookHandler) OnIncomingMessageExtendedText(callback WebhookCallback) {
-	w.callbacks[WebhookTypeIncomingMessageReceivedExtendedTextMessage] = callback
+	w.onRaw(callback, WebhookTypeIncomingMessageReceivedExtendedTextMessage)
 }
 
 // OnDeviceInfo registers a callback for device info events
 func (w *WebhookHandler) OnDeviceInfo(callback WebhookCallback) {
-	w.callbacks[WebhookTypeDeviceInfo] = callback
+	w.onRaw(callback, WebhookTypeDeviceInfo)
+}
+
+// On registers a handler for events of the given webhook types. A type without a
+// message kind, such as WebhookTypeIncomingMessageReceived, matches all its message kinds.
+func (w *WebhookHandler) On(handler Handler, types ...WebhookType) {
+	w.addRoute(route{match: ForType(types...), handler: handler})
+}
+
+// OnMatch registers a handler for the events accepted by the predicate
+func (w *WebhookHandler) OnMatch(match Predicate, handler Handler) {
+	w.addRoute(route{match: match, handler: handler})
+}
+
+// OnAny registers a handler that receives every event. Catch-all handlers do not
+// count as handling an event, so OnUnhandled handlers still run for unknown types.
+func (w *WebhookHandler) OnAny(handler Handler) {
+	w.addRoute(route{match: func(*WebhookEvent) bool { return true }, handler: handler, catchAll: true})
+}
+
+// OnUnhandled registers a fallback handler for events that no other handler subscribed to
+func (w *WebhookHandler) OnUnhandled(handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.unhandled = append(w.unhandled, handler)
 }
 
 // HandleWebhook processes a webhook request
 func (w *WebhookHandler) HandleWebhook(data map[string]interface{}) error {
-	var webhookType WebhookType
-
-	// Determine webhook type
-	if typeWebhook, ok := data["typeWebhook"].(string); ok {
-		if messageData, ok := data["messageData"].(map[string]interface{}); ok {
-			if typeMessage, ok := messageData["typeMessage"].(string); ok {
-				webhookType = WebhookType(fmt.Sprintf("%s_%s", typeWebhook, typeMessage))
-			}
-		} else {
-			webhookType = WebhookType(typeWebhook)
+	return w.HandleWebhookContext(context.Background(), data)
+}
+
+// HandleWebhookContext processes a webhook request, passing ctx to the handlers.
+// Queued notifications wrapped in a body member are unwrapped first.
+func (w *WebhookHandler) HandleWebhookContext(ctx context.Context, data map[string]interface{}) error {
+	ev, err := ParseWebhookEvent(data)
+	if err != nil {
+		return err
+	}
+	return w.HandleEvent(ctx, ev)
+}
+
+// HandleEvent passes a decoded event through the middleware chain and dispatches it to
+// every matching handler in registration order, stopping at the first handler that
+// returns an error. Events already handled are skipped when a Deduplicator is set.
+// Handling is traced in a consumer span, which is the parent of the handlers' spans.
+func (w *WebhookHandler) HandleEvent(ctx context.Context, ev *WebhookEvent) (err error) {
+	source, _ := ctx.Value(eventSourceKey{}).(string)
+	w.metrics.ObserveEventReceived(ev.Type(), source)
+
+	start := time.Now()
+	ctx, span := w.startEventSpan(ctx, ev)
+	defer func() {
+		endEventSpan(span, err)
+		w.metrics.ObserveEvent(ev.Type(), time.Since(start), err)
+	}()
+
+	w.mu.RLock()
+	middlewares := w.middlewares
+	w.mu.RUnlock()
+
+	handler := Handler(w.dispatch)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	if w.dedup != nil {
+		handler = deduplicate(w.dedup, handler)
+	}
+
+	return handler(ctx, ev)
+}
+
+// dispatch runs the handlers subscribed to an event
+func (w *WebhookHandler) dispatch(ctx context.Context, ev *WebhookEvent) error {
+	w.mu.RLock()
+	routes := w.routes
+	unhandled := w.unhandled
+	w.mu.RUnlock()
+
+	handled := false
+	for _, r := range routes {
+		if !r.match(ev) {
+			continue
+		}
+		if !r.catchAll {
+			handled = true
+		}
+		if err := r.handler(ctx, ev); err != nil {
+			return err
 		}
 	}
 
-	// Execute callback if registered
-	if callback, exists := w.callbacks[webhookType]; exists {
-		return callback(data)
+	if handled {
+		return nil
+	}
+
+	for _, handler := range unhandled {
+		if err := handler(ctx, ev); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// incomingMessageType returns the webhook type of an incoming message of the given kind
+func incomingMessageType(messageType MessageType) WebhookType {
+	return WebhookType(fmt.Sprintf("%s_%s", WebhookTypeIncomingMessageReceived, messageType))
+}
+
 // ServeHTTP implements http.Handler interface for webhook handling
 func (w *WebhookHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -112,146 +304,62 @@ func (w *WebhookHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var data map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-		http.Error(rw, "Invalid JSON", http.StatusBadRequest)
+	if !w.verifier.allowIP(r) {
+		http.Error(rw, "Forbidden", http.StatusForbidden)
 		return
 	}
 
-	if err := w.HandleWebhook(data); err != nil {
-		log.Printf("Error handling webhook: %v", err)
-		http.Error(rw, "Internal server error", http.StatusInternalServerError)
+	if !w.verifier.authorized(r) {
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	rw.WriteHeader(http.StatusOK)
-}
-
-// WebSocketClient handles WebSocket connections for real-time events
-type WebSocketClient struct {
-	conn     *websocket.Conn
-	client   *Client
-	handler  *WebhookHandler
-	stopChan chan struct{}
-}
-
-// NewWebSocketClient creates a new WebSocket client
-func (c *Client) NewWebSocketClient(handler *WebhookHandler) *WebSocketClient {
-	return &WebSocketClient{
-		client:   c,
-		handler:  handler,
-		stopChan: make(chan struct{}),
+	if w.verifier.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(rw, r.Body, w.verifier.maxBodySize)
 	}
-}
-
-// Connect establishes a WebSocket connection
-func (ws *WebSocketClient) Connect(ctx context.Context) error {
-	// Convert HTTP(S) URL to WebSocket URL
-	wsURL := strings.Replace(ws.client.apiHost, "http://", "ws://", 1)
-	wsURL = strings.Replace(wsURL, "https://", "wss://", 1)
-	wsURL = fmt.Sprintf("%s/ws/%s", wsURL, ws.client.idInstance)
-
-	// Add authorization as query parameter
-	u, err := url.Parse(wsURL)
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("invalid WebSocket URL: %w", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(rw, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(rw, "Failed to read request body", http.StatusBadRequest)
+		return
 	}
 
-	q := u.Query()
-	q.Set("token", ws.client.apiTokenInstance)
-	u.RawQuery = q.Encode()
-
-	// Create WebSocket connection
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
+	if !w.verifier.validSignature(r, body) {
+		http.Error(rw, "Invalid signature", http.StatusUnauthorized)
+		return
 	}
 
-	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		http.Error(rw, "Invalid JSON", http.StatusBadRequest)
+		return
 	}
 
-	ws.conn = conn
-	return nil
-}
-
-// Listen starts listening for WebSocket messages
-func (ws *WebSocketClient) Listen(ctx context.Context) error {
-	if ws.conn == nil {
-		return fmt.Errorf("WebSocket connection not established")
+	ev, err := ParseWebhookEvent(data)
+	if err != nil {
+		http.Error(rw, "Invalid webhook payload", http.StatusBadRequest)
+		return
 	}
 
-	defer ws.conn.Close()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ws.stopChan:
-			return nil
-		default:
-			var message map[string]interface{}
-			if err := ws.conn.ReadJSON(&message); err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					return fmt.Errorf("WebSocket error: %w", err)
-				}
-				return nil
-			}
-
-			// Handle the message using the webhook handler
-			if ws.handler != nil {
-				if err := ws.handler.HandleWebhook(message); err != nil {
-					log.Printf("Error handling WebSocket message: %v", err)
-				}
-			}
+	ctx := withEventSource(r.Context(), eventSourceWebhook)
+	if w.pool != nil {
+		if err := w.pool.enqueue(ctx, ev); err != nil {
+			http.Error(rw, "Service unavailable", http.StatusServiceUnavailable)
+			return
 		}
+		rw.WriteHeader(http.StatusOK)
+		return
 	}
-}
 
-// Close closes the WebSocket connection
-func (ws *WebSocketClient) Close() error {
-	close(ws.stopChan)
-	if ws.conn != nil {
-		return ws.conn.Close()
+	if err := w.HandleEvent(ctx, ev); err != nil {
+		w.logger.LogAttrs(ctx, slog.LevelError, "webhook handling failed", append(eventAttrs(ev), slog.Any("error", err))...)
+		http.Error(rw, "Internal server error", http.StatusInternalServerError)
+		return
 	}
-	return nil
-}
 
-// StartReceivingNotifications starts receiving notifications via polling
-func (c *Client) StartReceivingNotifications(ctx context.Context, handler *WebhookHandler) error {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			notification, err := c.ReceiveNotification(ctx)
-			if err != nil {
-				log.Printf("Error receiving notification: %v", err)
-				continue
-			}
-
-			// If notification is empty, continue
-			if len(notification) == 0 {
-				continue
-			}
-
-			// Handle the notification
-			if handler != nil {
-				if err := handler.HandleWebhook(notification); err != nil {
-					log.Printf("Error handling notification: %v", err)
-				}
-			}
-
-			// Delete the notification if it has a receiptId
-			if receiptIDFloat, ok := notification["receiptId"].(float64); ok {
-				receiptID := int64(receiptIDFloat)
-				if _, err := c.DeleteNotification(ctx, receiptID); err != nil {
-					log.Printf("Error deleting notification: %v", err)
-				}
-			}
-		}
-	}
+	rw.WriteHeader(http.StatusOK)
 }
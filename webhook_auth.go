@@ -0,0 +1,139 @@
+package sdkwa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// defaultSignatureHeader carries the HMAC body signature when WebhookOptions.SignatureHeader is unset
+const defaultSignatureHeader = "X-Signature"
+
+// webhookVerifier checks incoming webhook requests before their body is processed
+type webhookVerifier struct {
+	authToken         string
+	signatureSecret   []byte
+	signatureHeader   string
+	allowedNets       []*net.IPNet
+	trustForwardedFor bool
+	maxBodySize       int64
+}
+
+// newWebhookVerifier validates the verification settings of the options
+func newWebhookVerifier(opts WebhookOptions) (*webhookVerifier, error) {
+	v := &webhookVerifier{
+		authToken:         opts.AuthToken,
+		signatureSecret:   opts.SignatureSecret,
+		signatureHeader:   opts.SignatureHeader,
+		trustForwardedFor: opts.TrustForwardedFor,
+		maxBodySize:       opts.MaxBodySize,
+	}
+	if v.signatureHeader == "" {
+		v.signatureHeader = defaultSignatureHeader
+	}
+
+	for _, entry := range opts.AllowedIPs {
+		ipNet, err := parseIPOrCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		v.allowedNets = append(v.allowedNets, ipNet)
+	}
+
+	return v, nil
+}
+
+// parseIPOrCIDR parses a single address or a CIDR range
+func parseIPOrCIDR(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed IP range %q: %w", entry, err)
+		}
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid allowed IP %q", entry)
+	}
+	bits := 8 * net.IPv4len
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// allowIP reports whether the request comes from an allowed address
+func (v *webhookVerifier) allowIP(r *http.Request) bool {
+	if len(v.allowedNets) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(v.remoteIP(r))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range v.allowedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the client address, taken from the last X-Forwarded-For entry
+// (the one added by the trusted reverse proxy) when TrustForwardedFor is set
+func (v *webhookVerifier) remoteIP(r *http.Request) string {
+	if v.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			return strings.TrimSpace(parts[len(parts)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// authorized checks the Authorization header against the webhook token.
+// Both "Bearer <token>" and the bare token are accepted.
+func (v *webhookVerifier) authorized(r *http.Request) bool {
+	if v.authToken == "" {
+		return true
+	}
+
+	header := r.Header.Get("Authorization")
+	token := header
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		token = header[len("Bearer "):]
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(v.authToken)) == 1
+}
+
+// validSignature checks the hex-encoded HMAC-SHA256 signature of the body,
+// optionally prefixed with "sha256="
+func (v *webhookVerifier) validSignature(r *http.Request, body []byte) bool {
+	if len(v.signatureSecret) == 0 {
+		return true
+	}
+
+	signature := strings.TrimPrefix(r.Header.Get(v.signatureHeader), "sha256=")
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, v.signatureSecret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,90 @@
+package sdkwa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stateChangedJSON = `{"typeWebhook":"stateInstanceChanged","stateInstance":"authorized"}`
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestWebhookHandler_Verification tests token, signature, IP and body size checks in ServeHTTP
+func TestWebhookHandler_Verification(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{
+		AuthToken:       "secret-token",
+		SignatureSecret: []byte("hmac-secret"),
+		AllowedIPs:      []string{"10.0.0.0/8", "192.168.1.10"},
+		MaxBodySize:     256,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		auth       string
+		signature  string
+		body       string
+		wantStatus int
+	}{
+		{"valid bearer", "10.1.2.3:1234", "Bearer secret-token", sign("hmac-secret", stateChangedJSON), stateChangedJSON, http.StatusOK},
+		{"valid bare token", "192.168.1.10:1234", "secret-token", "sha256=" + sign("hmac-secret", stateChangedJSON), stateChangedJSON, http.StatusOK},
+		{"ip not allowed", "172.16.0.1:1234", "Bearer secret-token", sign("hmac-secret", stateChangedJSON), stateChangedJSON, http.StatusForbidden},
+		{"missing token", "10.1.2.3:1234", "", sign("hmac-secret", stateChangedJSON), stateChangedJSON, http.StatusUnauthorized},
+		{"wrong token", "10.1.2.3:1234", "Bearer nope", sign("hmac-secret", stateChangedJSON), stateChangedJSON, http.StatusUnauthorized},
+		{"bad signature", "10.1.2.3:1234", "Bearer secret-token", sign("other", stateChangedJSON), stateChangedJSON, http.StatusUnauthorized},
+		{"body too large", "10.1.2.3:1234", "Bearer secret-token", "", `{"typeWebhook":"x","pad":"` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.RemoteAddr = tt.remoteAddr
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.signature != "" {
+				req.Header.Set("X-Signature", tt.signature)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// TestWebhookHandler_ForwardedFor tests the client address taken from a trusted proxy
+func TestWebhookHandler_ForwardedFor(t *testing.T) {
+	handler, err := NewWebhookHandlerWithOptions(WebhookOptions{
+		AllowedIPs:        []string{"203.0.113.7"},
+		TrustForwardedFor: true,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(stateChangedJSON))
+	req.RemoteAddr = "127.0.0.1:5555"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestNewWebhookHandlerWithOptions_InvalidIP tests validation of the allowlist
+func TestNewWebhookHandlerWithOptions_InvalidIP(t *testing.T) {
+	_, err := NewWebhookHandlerWithOptions(WebhookOptions{AllowedIPs: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,426 @@
+package sdkwa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketState describes the connection state of a WebSocketClient
+type WebSocketState int
+
+const (
+	WebSocketDisconnected WebSocketState = iota // Not connected
+	WebSocketConnecting                         // Dialing for the first time
+	WebSocketConnected                          // Connected and receiving events
+	WebSocketReconnecting                       // Waiting to dial again after the connection failed
+)
+
+// String returns the name of the state
+func (s WebSocketState) String() string {
+	switch s {
+	case WebSocketDisconnected:
+		return "disconnected"
+	case WebSocketConnecting:
+		return "connecting"
+	case WebSocketConnected:
+		return "connected"
+	case WebSocketReconnecting:
+		return "reconnecting"
+	default:
+		return fmt.Sprintf("WebSocketState(%d)", int(s))
+	}
+}
+
+// WebSocketOptions configures the connection handling of a WebSocketClient
+type WebSocketOptions struct {
+	AutoReconnect    bool          // Reconnect with exponential backoff when Run loses the connection
+	MinBackoff       time.Duration // Delay before the first reconnection attempt, defaults to 1s
+	MaxBackoff       time.Duration // Maximum delay between reconnection attempts, defaults to 1m
+	PingInterval     time.Duration // Interval between keepalive pings, defaults to 30s; negative disables keepalive
+	PongWait         time.Duration // Time without pong or message after which the connection is considered dead, defaults to twice PingInterval
+	HandshakeTimeout time.Duration // Timeout of the WebSocket handshake, defaults to 10s
+
+	// OnStateChange is called on every state transition. err holds the reason of
+	// WebSocketDisconnected and WebSocketReconnecting transitions.
+	OnStateChange func(state WebSocketState, err error)
+}
+
+// withDefaults returns a copy of the options with zero values replaced by defaults
+func (o WebSocketOptions) withDefaults() WebSocketOptions {
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Minute
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = o.MinBackoff
+	}
+	if o.PingInterval == 0 {
+		o.PingInterval = 30 * time.Second
+	}
+	if o.PongWait <= 0 || o.PongWait <= o.PingInterval {
+		o.PongWait = 2 * o.PingInterval
+	}
+	if o.HandshakeTimeout <= 0 {
+		o.HandshakeTimeout = 10 * time.Second
+	}
+	return o
+}
+
+// backoff returns the reconnection delay policy
+func (o WebSocketOptions) backoff() RetryPolicy {
+	return RetryPolicy{BaseDelay: o.MinBackoff, MaxDelay: o.MaxBackoff, Jitter: 0.2}
+}
+
+// pingWriteWait limits the time spent sending a keepalive ping
+const pingWriteWait = 10 * time.Second
+
+// closeWriteWait limits the time spent sending the close frame in Close
+const closeWriteWait = time.Second
+
+// errWebSocketClientClosed is returned by Connect when Close is called while dialing
+var errWebSocketClientClosed = errors.New("WebSocket client closed")
+
+// WebSocketCloseError is returned by Listen and Run when the server closes the connection
+type WebSocketCloseError struct {
+	Code int    // Close code sent by the server, see RFC 6455 section 7.4
+	Text string // Close reason sent by the server
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("WebSocket closed by server with code %d", e.Code)
+	}
+	return fmt.Sprintf("WebSocket closed by server with code %d: %s", e.Code, e.Text)
+}
+
+// WebSocketClient handles WebSocket connections for real-time events. Close may be
+// called concurrently with Listen and Run and more than once; the client can be
+// connected again afterwards.
+type WebSocketClient struct {
+	client  *Client
+	handler *WebhookHandler
+	opts    WebSocketOptions
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	stopChan chan struct{} // closed by Close, replaced when the client is reused
+	closed   bool
+	state    WebSocketState
+}
+
+// NewWebSocketClient creates a new WebSocket client with default options
+func (c *Client) NewWebSocketClient(handler *WebhookHandler) *WebSocketClient {
+	return c.NewWebSocketClientWithOptions(handler, WebSocketOptions{})
+}
+
+// NewWebSocketClientWithOptions creates a new WebSocket client with custom options
+func (c *Client) NewWebSocketClientWithOptions(handler *WebhookHandler, opts WebSocketOptions) *WebSocketClient {
+	return &WebSocketClient{
+		client:   c,
+		handler:  handler,
+		opts:     opts.withDefaults(),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// State returns the current connection state
+func (ws *WebSocketClient) State() WebSocketState {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.state
+}
+
+// setState records a state transition and notifies OnStateChange
+func (ws *WebSocketClient) setState(state WebSocketState, err error) {
+	ws.mu.Lock()
+	ws.state = state
+	ws.mu.Unlock()
+
+	if err != nil {
+		ws.client.logger.Info("WebSocket state changed", "state", state.String(), "error", err)
+	} else {
+		ws.client.logger.Info("WebSocket state changed", "state", state.String())
+	}
+
+	if ws.opts.OnStateChange != nil {
+		ws.opts.OnStateChange(state, err)
+	}
+}
+
+// reopen makes a closed client usable again and returns the channel closed by the next Close
+func (ws *WebSocketClient) reopen() chan struct{} {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed {
+		ws.stopChan = make(chan struct{})
+		ws.closed = false
+	}
+	return ws.stopChan
+}
+
+// Connect establishes a WebSocket connection, replacing the current one if any.
+// A client stopped by Close can be connected again.
+func (ws *WebSocketClient) Connect(ctx context.Context) error {
+	return ws.connect(ctx, ws.reopen())
+}
+
+// connect dials the server unless stop is closed before the connection is established
+func (ws *WebSocketClient) connect(ctx context.Context, stop chan struct{}) error {
+	// Convert HTTP(S) URL to WebSocket URL
+	wsURL := strings.Replace(ws.client.apiHost, "http://", "ws://", 1)
+	wsURL = strings.Replace(wsURL, "https://", "wss://", 1)
+	wsURL = fmt.Sprintf("%s/ws/%s", wsURL, ws.client.idInstance)
+
+	// Add authorization as query parameter
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return fmt.Errorf("invalid WebSocket URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("token", ws.client.apiTokenInstance)
+	u.RawQuery = q.Encode()
+
+	// Create WebSocket connection through the same proxy and TLS settings as API requests
+	proxy, tlsConfig := ws.client.connectionSettings()
+	dialer := websocket.Dialer{
+		Proxy:            proxy,
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: ws.opts.HandshakeTimeout,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if isClosed(stop) {
+		conn.Close()
+		return errWebSocketClientClosed
+	}
+	if ws.conn != nil {
+		ws.conn.Close()
+	}
+	ws.conn = conn
+	return nil
+}
+
+// Listen reads and handles messages until the connection ends. It returns nil after
+// Close, the context error when ctx is cancelled (which interrupts a pending read
+// immediately), a *WebSocketCloseError when the server closes the connection and
+// the read error when the connection fails or stops answering keepalive pings.
+func (ws *WebSocketClient) Listen(ctx context.Context) error {
+	ws.mu.Lock()
+	conn, stop := ws.conn, ws.stopChan
+	ws.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("WebSocket connection not established")
+	}
+
+	defer func() {
+		conn.Close()
+		ws.mu.Lock()
+		if ws.conn == conn {
+			ws.conn = nil
+		}
+		ws.mu.Unlock()
+	}()
+
+	stopHeartbeat := ws.startHeartbeat(conn)
+	defer stopHeartbeat()
+
+	// Closing the connection is the only way to interrupt a blocking read
+	listening := make(chan struct{})
+	defer close(listening)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-listening:
+		}
+	}()
+
+	for {
+		// The deadline is re-armed before every read rather than after it, so that the
+		// time spent in handlers, during which pongs are not processed, is not counted
+		ws.extendReadDeadline(conn)
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return listenError(ctx, stop, err)
+		}
+
+		var message map[string]interface{}
+		if err := json.Unmarshal(data, &message); err != nil {
+			ws.client.logger.WarnContext(ctx, "failed to decode WebSocket message", "error", err)
+			continue
+		}
+
+		// Handle the message using the webhook handler
+		if ws.handler != nil {
+			if err := ws.handler.HandleWebhookContext(withEventSource(ctx, eventSourceWebSocket), message); err != nil {
+				ws.client.logger.ErrorContext(ctx, "WebSocket message handling failed", "error", err)
+			}
+		}
+	}
+}
+
+// listenError converts the error that ended a read into the reason Listen returns
+func listenError(ctx context.Context, stop chan struct{}, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if isClosed(stop) {
+		return nil
+	}
+
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		return &WebSocketCloseError{Code: closeErr.Code, Text: closeErr.Text}
+	}
+	return fmt.Errorf("WebSocket error: %w", err)
+}
+
+// Run connects and listens until ctx is cancelled or Close is called. With
+// AutoReconnect it reconnects with exponential backoff whenever dialing or the
+// connection fails; otherwise it returns the first failure. Run returns nil after
+// Close and the context error after cancellation.
+func (ws *WebSocketClient) Run(ctx context.Context) error {
+	stop := ws.reopen()
+	backoff := ws.opts.backoff()
+	ws.setState(WebSocketConnecting, nil)
+
+	failures := 0
+	for {
+		err := ws.connect(ctx, stop)
+		if err == nil {
+			failures = 0
+			ws.setState(WebSocketConnected, nil)
+			err = ws.Listen(ctx)
+		}
+
+		if ctx.Err() != nil {
+			ws.setState(WebSocketDisconnected, ctx.Err())
+			return ctx.Err()
+		}
+		if isClosed(stop) {
+			ws.setState(WebSocketDisconnected, nil)
+			return nil
+		}
+
+		ws.setState(WebSocketDisconnected, err)
+		if !ws.opts.AutoReconnect {
+			return err
+		}
+
+		failures++
+		ws.setState(WebSocketReconnecting, err)
+		ws.client.metrics.ObserveWebSocketReconnect()
+
+		timer := time.NewTimer(backoff.delay(failures, nil))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			ws.setState(WebSocketDisconnected, ctx.Err())
+			return ctx.Err()
+		case <-stop:
+			timer.Stop()
+			ws.setState(WebSocketDisconnected, nil)
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Close sends a close frame and closes the WebSocket connection, stopping Listen and
+// Run. Calling Close again has no effect.
+func (ws *WebSocketClient) Close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed {
+		return nil
+	}
+	ws.closed = true
+	close(ws.stopChan)
+
+	if ws.conn == nil {
+		return nil
+	}
+	conn := ws.conn
+	ws.conn = nil
+
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(closeWriteWait))
+	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// isClosed reports whether ch is closed
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// startHeartbeat sets the read deadline of conn and pings the server periodically,
+// extending the deadline on every pong. It returns a function stopping the pings.
+func (ws *WebSocketClient) startHeartbeat(conn *websocket.Conn) func() {
+	if ws.opts.PingInterval < 0 {
+		return func() {}
+	}
+
+	ws.extendReadDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		ws.extendReadDeadline(conn)
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ws.opts.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteWait)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// extendReadDeadline gives the server another PongWait to send a message or pong
+func (ws *WebSocketClient) extendReadDeadline(conn *websocket.Conn) {
+	if ws.opts.PingInterval < 0 {
+		return
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(ws.opts.PongWait))
+}
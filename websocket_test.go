@@ -0,0 +1,219 @@
+package sdkwa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebSocketServer starts a server upgrading every request and passing the connection
+// and its sequence number, starting at 1, to serve
+func newWebSocketServer(t *testing.T, serve func(n int, conn *websocket.Conn)) *httptest.Server {
+	var upgrader websocket.Upgrader
+	var connections int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(int(atomic.AddInt32(&connections, 1)), conn)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestWebSocketClient_RunReconnects tests that Run reconnects after a dropped connection
+func TestWebSocketClient_RunReconnects(t *testing.T) {
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		if n == 1 {
+			return // drop the first connection without a close frame
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(stateChangedJSON))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := NewWebhookHandler()
+	handler.OnStateChanged(func(ctx context.Context, ev *StateChangedEvent) error {
+		cancel()
+		return nil
+	})
+
+	var mu sync.Mutex
+	var states []WebSocketState
+	ws := client.NewWebSocketClientWithOptions(handler, WebSocketOptions{
+		AutoReconnect: true,
+		MinBackoff:    10 * time.Millisecond,
+		OnStateChange: func(state WebSocketState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			states = append(states, state)
+		},
+	})
+
+	err := ws.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []WebSocketState{
+		WebSocketConnecting,
+		WebSocketConnected,
+		WebSocketDisconnected,
+		WebSocketReconnecting,
+		WebSocketConnected,
+		WebSocketDisconnected,
+	}, states)
+	assert.Equal(t, WebSocketDisconnected, ws.State())
+}
+
+// TestWebSocketClient_PongTimeout tests that a connection without pongs is considered dead
+func TestWebSocketClient_PongTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// The server never reads, so pings are never answered
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		<-release
+	})
+	client := newTestClient(t, server, Options{})
+
+	ws := client.NewWebSocketClientWithOptions(nil, WebSocketOptions{
+		PingInterval: 10 * time.Millisecond,
+		PongWait:     50 * time.Millisecond,
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- ws.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timeout")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not detect the dead connection")
+	}
+}
+
+// TestWebSocketClient_SlowHandler tests that handlers slower than PongWait do not drop a healthy connection
+func TestWebSocketClient_SlowHandler(t *testing.T) {
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(stateChangedJSON))
+		go func() {
+			// Sent while the first message is being handled
+			time.Sleep(150 * time.Millisecond)
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(stateChangedJSON))
+		}()
+		for {
+			// Reading answers the client's pings
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	client := newTestClient(t, server, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handled := 0
+	handler := NewWebhookHandler()
+	handler.OnStateChanged(func(ctx context.Context, ev *StateChangedEvent) error {
+		time.Sleep(300 * time.Millisecond)
+		handled++
+		if handled == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	ws := client.NewWebSocketClientWithOptions(handler, WebSocketOptions{
+		PingInterval: 50 * time.Millisecond,
+		PongWait:     100 * time.Millisecond,
+	})
+
+	assert.ErrorIs(t, ws.Run(ctx), context.Canceled)
+	assert.Equal(t, 2, handled)
+}
+
+// TestWebSocketClient_ListenCancel tests that cancellation interrupts a pending read
+func TestWebSocketClient_ListenCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		<-release
+	})
+	ws := newTestClient(t, server, Options{}).NewWebSocketClient(nil)
+	require.NoError(t, ws.Connect(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, ws.Listen(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestWebSocketClient_ServerClose tests the error returned when the server closes the connection
+func TestWebSocketClient_ServerClose(t *testing.T) {
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4001, "instance logged out"))
+	})
+	ws := newTestClient(t, server, Options{}).NewWebSocketClient(nil)
+	require.NoError(t, ws.Connect(context.Background()))
+
+	err := ws.Listen(context.Background())
+
+	var closeErr *WebSocketCloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4001, closeErr.Code)
+	assert.Equal(t, "instance logged out", closeErr.Text)
+}
+
+// TestWebSocketClient_CloseAndReuse tests that Close stops Listen, is idempotent and allows reconnecting
+func TestWebSocketClient_CloseAndReuse(t *testing.T) {
+	server := newWebSocketServer(t, func(n int, conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	ws := newTestClient(t, server, Options{}).NewWebSocketClient(nil)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ws.Connect(context.Background()))
+
+		done := make(chan error, 1)
+		go func() { done <- ws.Listen(context.Background()) }()
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, ws.Close())
+		require.NoError(t, ws.Close())
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Listen did not return after Close")
+		}
+	}
+}